@@ -1,5 +1,13 @@
 package client
 
+import (
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"github.com/lazybark/go-tls-server/conn"
+)
+
 type Config struct {
 	// SuppressErrors prevents client from sending errors into ErrChan.
 	// Does not include fatal errors during startup.
@@ -16,9 +24,58 @@ type Config struct {
 	// Works for both incoming and outgoing messages.
 	MessageTerminator byte
 
+	// MessageDelimiter sets multi-byte sequence that marks end of the message in stream, e.g. "\r\n".
+	// Overrides MessageTerminator if not empty.
+	MessageDelimiter []byte
+
+	// MessageEscaping turns on escaping of MessageTerminator (or MessageDelimiter) inside messages,
+	// so payload may contain any bytes without splitting one message into two.
+	// Both peers must have the same value.
+	// Ignored if Framer is set.
+	MessageEscaping bool
+
+	// Framer sets the way messages are separated in the stream. Both peers must use the same framing.
+	// Use conn.NewLengthPrefixFramer to send arbitrary binary payloads.
+	//
+	// Default: conn.TerminatorFramer with MessageTerminator / MessageDelimiter and MessageEscaping.
+	Framer conn.Framer
+
+	// PlainUnixSockets turns off TLS when dialing Unix domain socket ("unix:///path.sock" addresses).
+	// Must match server's PlainUnixSockets.
+	PlainUnixSockets bool
+
+	// TLSPolicy sets TLS versions, cipher suites, curves, ALPN protocols and session tickets client allows.
+	// It overrides the same settings of TLS config passed to DialWithConfig. See conn.ModernTLSPolicy
+	// and conn.CompatibleTLSPolicy for presets. Invalid policy makes dialing return error before connecting,
+	// Config.Validate checks it before client is made.
+	//
+	// Default: TLS 1.2 is the minimal version, other settings are defaults of crypto/tls.
+	TLSPolicy *conn.TLSPolicy
+
+	// Certificates are presented to server that requires client certificates (mutual TLS).
+	// Ignored by DialWithConfig if its config has own certificates.
+	Certificates []tls.Certificate
+
+	// DialTimeout limits dialing to one endpoint, context passed to DialContext may limit it more.
+	//
+	// Default: 3 seconds.
+	DialTimeout time.Duration
+
 	// BufferSize regulates buffer length to read incoming message. Default value is 128.
 	BufferSize int
 
+	// Reconnect makes client dial again once connection is broken (see ReconnectPolicy).
+	// Messages keep coming into the same message channel. Nil means client doesn't reconnect.
+	Reconnect *ReconnectPolicy
+
+	// Failover sets order of endpoints passed to DialEndpoints and time failed ones are skipped
+	// (see FailoverPolicy). Nil means endpoints are tried in order they were listed.
+	Failover *FailoverPolicy
+
+	// Outbox makes client queue outgoing messages while it's disconnected and send them in order
+	// once connection is made (see OutboxPolicy). Nil means sending fails while client is disconnected.
+	Outbox *OutboxPolicy
+
 	// DropOldStats = true will make client to set all sent/received bytes & errors to zero before opening new connection.
 	DropOldStats bool
 
@@ -27,3 +84,15 @@ type Config struct {
 	// Default: "TLS_CLIENT".
 	ErrorPrefix string
 }
+
+// Validate checks config before it's passed to New or NewPool. It returns error wrapping
+// conn.ErrInvalidTLSPolicy if TLSPolicy is invalid, the same error dialing would return.
+func (c *Config) Validate() error {
+	if c.TLSPolicy != nil {
+		if err := c.TLSPolicy.Validate(); err != nil {
+			return fmt.Errorf("[Config] %w", err)
+		}
+	}
+
+	return nil
+}
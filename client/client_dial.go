@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
@@ -11,54 +12,255 @@ import (
 	"github.com/lazybark/go-tls-server/conn"
 )
 
+const (
+	// unixServerName is the name client verifies in server certificate when dialing Unix domain socket over TLS.
+	unixServerName = "localhost"
+	// defaultDialTimeout limits dialing to one endpoint if Config.DialTimeout is 0.
+	defaultDialTimeout = 3 * time.Second
+)
+
 // DialTo dials to specified server and port using cert if provided.
 // If cert is not provided and server has self-signed cert, DialTo will return
 // 'certificate signed by unknown authority' error.
 func (c *Client) DialTo(address string, port int, cert string) error {
-	var config tls.Config
+	return c.Dial(fmt.Sprintf("%s:%d", address, port), cert)
+}
 
-	if cert != "" {
-		certificate, err := os.ReadFile(cert)
-		if err != nil {
-			return c.FormatError(fmt.Errorf("unable to read file: %w", err))
-		}
+// Dial dials to server at addr (host:port or "unix:///path.sock") using cert file if provided.
+// Unix domain socket is dialed over TLS with server name "localhost", unless PlainUnixSockets is set.
+func (c *Client) Dial(addr string, cert string) error {
+	if cert == "" {
+		return c.DialWithConfig(addr, nil)
+	}
+
+	certificate, err := os.ReadFile(cert)
+	if err != nil {
+		return c.FormatError(fmt.Errorf("unable to read file: %w", err))
+	}
 
-		certPool := x509.NewCertPool()
+	return c.DialWithCA(addr, certificate)
+}
 
-		if ok := certPool.AppendCertsFromPEM(certificate); !ok {
-			return c.FormatError(fmt.Errorf("unable to parse cert from %s: %w", cert, err))
-		}
+// DialWithCA dials to server at addr and verifies server certificate by PEM-encoded CA certificates.
+func (c *Client) DialWithCA(addr string, caPEM []byte) error {
+	certPool := x509.NewCertPool()
 
-		config = tls.Config{}
-		config.RootCAs = certPool
-		config.MinVersion = tls.VersionTLS12
+	if ok := certPool.AppendCertsFromPEM(caPEM); !ok {
+		return c.FormatError(ErrInvalidCA)
 	}
 
-	tlsConn, err := tls.DialWithDialer(
-		&net.Dialer{Timeout: 3 * time.Second}, "tcp", fmt.Sprintf("%s:%d", address, port), &config)
+	return c.DialWithConfig(addr, &tls.Config{RootCAs: certPool, MinVersion: tls.VersionTLS12})
+}
+
+// DialWithConfig dials to server at addr using copy of config (e.g. with client certificates
+// or custom verification). Nil config means system CA pool is used to verify server.
+// Config.TLSPolicy is applied on top of config, TLS 1.2 is the minimal version by default.
+// Invalid TLSPolicy is rejected before connecting with error wrapping conn.ErrInvalidTLSPolicy.
+func (c *Client) DialWithConfig(addr string, config *tls.Config) error {
+	return c.DialEndpoints([]string{addr}, config)
+}
+
+// DialEndpoints dials to one of servers at addrs using copy of config (see DialWithConfig).
+// Endpoints are tried in order set by Config.Failover, failed ones are marked unhealthy and tried last.
+// Reconnect uses the same list, so client fails over to another server once connection is broken.
+// Invalid Config.TLSPolicy is rejected before connecting (see Config.Validate).
+func (c *Client) DialEndpoints(addrs []string, config *tls.Config) error {
+	return c.dialEndpoints(context.Background(), addrs, config)
+}
+
+// DialOption sets optional parameters of DialContext.
+type DialOption func(*dialOptions)
+
+// dialOptions holds parameters set by DialOption.
+type dialOptions struct {
+	config    *tls.Config
+	endpoints []string
+}
+
+// WithTLSConfig makes DialContext use copy of config (see DialWithConfig).
+func WithTLSConfig(config *tls.Config) DialOption {
+	return func(o *dialOptions) { o.config = config }
+}
+
+// WithEndpoints adds endpoints DialContext fails over to if addr is unavailable (see DialEndpoints).
+func WithEndpoints(addrs ...string) DialOption {
+	return func(o *dialOptions) { o.endpoints = append(o.endpoints, addrs...) }
+}
+
+// DialContext dials to server at addr the same way as DialWithConfig, but ctx limits the time of dialing
+// together with Config.DialTimeout. If ctx is done before connection is made, it returns *conn.CanceledError.
+// Connection is not bound to ctx after dial. Invalid Config.TLSPolicy is rejected before connecting
+// (see Config.Validate).
+func (c *Client) DialContext(ctx context.Context, addr string, opts ...DialOption) error {
+	options := dialOptions{config: nil, endpoints: nil}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return c.dialEndpoints(ctx, append([]string{addr}, options.endpoints...), options.config)
+}
+
+// dialEndpoints dials to one of servers at addrs until ctx is done.
+func (c *Client) dialEndpoints(ctx context.Context, addrs []string, config *tls.Config) error {
+	if len(addrs) == 0 {
+		return c.FormatError(fmt.Errorf("dial: %w", ErrNoEndpoints))
+	}
+
+	if config == nil {
+		config = &tls.Config{} //nolint:gosec // MinVersion is set by TLS policy
+	}
+
+	config = config.Clone()
+	if len(config.Certificates) == 0 {
+		config.Certificates = c.conf.Certificates
+	}
+
+	policy := conn.TLSPolicy{} //nolint:exhaustruct // Empty policy keeps TLS config as is
+	if c.conf.TLSPolicy != nil {
+		policy = *c.conf.TLSPolicy
+	}
+
+	err := policy.Validate()
 	if err != nil {
-		return c.FormatError(fmt.Errorf("unable to dial to %s:%d: %w", address, port, err))
+		return c.FormatError(fmt.Errorf("dial: %w", err))
+	}
+
+	policy.Apply(config)
+
+	failover := FailoverPolicy{} //nolint:exhaustruct // Defaults are set by newEndpoints
+	if c.conf.Failover != nil {
+		failover = *c.conf.Failover
+	}
+
+	list := newEndpoints(addrs, failover)
+
+	netConn, addr, err := c.connectAny(ctx, list, config)
+	if err != nil {
+		return c.FormatError(err)
 	}
 
 	// We reset data in case client was used before.
+	c.mu.Lock()
 	c.isClosed = false
 	c.isClosedWithError = false
-	c.host = address
-	// Clean stats in case DropOldStats is true.
-	if c.conf.DropOldStats && c.connCount > 0 {
-		c.conn.DropOldStats()
+	c.done = make(chan struct{})
+	c.addr = addr
+	c.endpoints = list
+	c.tlsConfig = config
+	done := c.done
+	c.mu.Unlock()
+
+	c.host = addr
+
+	err = c.attach(netConn, done)
+	if err != nil {
+		return c.FormatError(fmt.Errorf("dial: %w", err))
 	}
 
-	cn, err := conn.NewConnection(tlsConn.RemoteAddr(), tlsConn, c.conf.MessageTerminator)
+	go c.controller()
+
+	c.emit(StateConnected, 0, nil)
+	c.flushOutbox()
+
+	return nil
+}
+
+// connectAny makes network connection to the first endpoint of list that accepts it.
+// It returns address of that endpoint or error of the last one that failed.
+// Endpoints are not marked failed if ctx is done while dialing.
+func (c *Client) connectAny(ctx context.Context, list *endpoints, config *tls.Config) (net.Conn, string, error) {
+	var err error
+
+	for _, addr := range list.order() {
+		if ctx.Err() != nil {
+			return nil, "", &conn.CanceledError{Op: "dial", Err: ctx.Err()}
+		}
+
+		var netConn net.Conn
+
+		netConn, err = c.connect(ctx, addr, config)
+		if err != nil && ctx.Err() != nil {
+			return nil, "", &conn.CanceledError{Op: "dial", Err: ctx.Err()}
+		}
+
+		if err == nil {
+			list.markHealthy(addr)
+
+			return netConn, addr, nil
+		}
+
+		list.markFailed(addr)
+		err = fmt.Errorf("unable to dial to %s: %w", addr, err)
+	}
+
+	return nil, "", err
+}
+
+// connect makes network connection to addr using config. Dialing is limited by ctx and Config.DialTimeout.
+func (c *Client) connect(ctx context.Context, addr string, config *tls.Config) (net.Conn, error) {
+	timeout := c.conf.DialTimeout
+	if timeout <= 0 {
+		timeout = defaultDialTimeout
+	}
+
+	network, address := conn.ParseAddress(addr)
+	dialer := &net.Dialer{Timeout: timeout}
+
+	switch {
+	case network == "unix" && c.conf.PlainUnixSockets:
+		return dialer.DialContext(ctx, network, address)
+	case network == "unix":
+		if config.ServerName == "" {
+			config = config.Clone()
+			config.ServerName = unixServerName
+		}
+
+		return (&tls.Dialer{NetDialer: dialer, Config: config}).DialContext(ctx, network, address)
+	default:
+		return (&tls.Dialer{NetDialer: dialer, Config: config}).DialContext(ctx, network, address)
+	}
+}
+
+// attach makes netConn the current connection of client and starts reading from it.
+// It closes netConn and returns conn.ErrConnectionClosed if client was closed since done was made.
+func (c *Client) attach(netConn net.Conn, done chan struct{}) error {
+	cn, err := conn.NewConnection(netConn.RemoteAddr(), netConn, c.conf.MessageTerminator)
 	if err != nil {
-		return c.FormatError(fmt.Errorf("dial: error making connection for %v: %w", tlsConn.RemoteAddr(), err))
+		_ = netConn.Close()
+
+		return fmt.Errorf("error making connection for %v: %w", netConn.RemoteAddr(), err)
+	}
+
+	cn.SetFramer(c.conf.Framer)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-done:
+		_ = netConn.Close()
+
+		return conn.ErrConnectionClosed
+	default:
+	}
+
+	// Clean stats in case DropOldStats is true.
+	if c.conf.DropOldStats && c.connCount > 0 {
+		c.conn.DropOldStats()
 	}
 
 	c.conn = cn
 	c.connCount++
 
-	go c.controller()
-	go c.reader()
+	go c.reader(cn, done)
 
 	return nil
 }
+
+// flushOutbox sends messages queued while client was disconnected. If connection breaks during flush,
+// messages are kept for the next connection and reader reports disconnection.
+func (c *Client) flushOutbox() {
+	if c.outbox != nil {
+		_ = c.outbox.flush(c.connection())
+	}
+}
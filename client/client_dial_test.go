@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/lazybark/go-tls-server/conn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialContext(t *testing.T) {
+	srv, connections := newTestServer(t, "127.0.0.1:0")
+	addr := srv.Addrs()[0].String()
+
+	// Listener that never makes TLS handshake.
+	silent, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer silent.Close()
+
+	go func() {
+		var accepted []net.Conn
+
+		for {
+			c, err := silent.Accept()
+			if err != nil {
+				for _, c := range accepted {
+					_ = c.Close()
+				}
+
+				return
+			}
+
+			accepted = append(accepted, c)
+		}
+	}()
+
+	c := New(&Config{SuppressErrors: true})
+
+	var canceled *conn.CanceledError
+
+	// Dialing is stopped by ctx.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	err = c.DialContext(ctx, silent.Addr().String(), WithTLSConfig(testTLSConfig), WithEndpoints(addr))
+	assert.True(t, errors.As(err, &canceled))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// Dialing is stopped by DialTimeout, client fails over to the next endpoint.
+	c = New(&Config{SuppressErrors: true, DialTimeout: time.Millisecond * 50})
+
+	err = c.DialContext(context.Background(), silent.Addr().String(), WithTLSConfig(testTLSConfig), WithEndpoints(addr))
+	require.NoError(t, err)
+	assert.Equal(t, addr, c.Endpoint())
+
+	connection := <-connections
+
+	count, err := c.SendContext(context.Background(), []byte("one"))
+	require.NoError(t, err)
+	assert.Equal(t, len("one\n"), count)
+
+	message, err := connection.GetMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "one", string(message.Bytes()))
+
+	// Done ctx stops send.
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+
+	_, err = c.SendContext(ctx, []byte("two"))
+	assert.True(t, errors.As(err, &canceled))
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	require.NoError(t, c.Close())
+	require.NoError(t, srv.Stop())
+}
+
+func TestConfigValidate(t *testing.T) {
+	conf := &Config{TLSPolicy: &conn.TLSPolicy{MinVersion: tls.VersionTLS10}}
+	assert.True(t, errors.Is(conf.Validate(), conn.ErrInvalidTLSPolicy))
+
+	// Dialing rejects the same policy before connecting.
+	err := New(conf).DialWithConfig(unusedAddr(t), testTLSConfig)
+	assert.True(t, errors.Is(err, conn.ErrInvalidTLSPolicy))
+
+	err = NewPool(&PoolConfig{Client: conf}).DialWithConfig(unusedAddr(t), testTLSConfig)
+	assert.True(t, errors.Is(err, conn.ErrInvalidTLSPolicy))
+
+	modern := conn.ModernTLSPolicy()
+	assert.NoError(t, (&Config{TLSPolicy: &modern}).Validate())
+	assert.NoError(t, (&Config{}).Validate())
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrNoEndpoints is returned when client is asked to dial empty list of endpoints.
+var ErrNoEndpoints = errors.New("no endpoints to dial")
+
+// defaultRetryAfter is the time failed endpoint is skipped if FailoverPolicy.RetryAfter is 0.
+const defaultRetryAfter = 30 * time.Second
+
+// Strategy sets order in which client tries endpoints.
+type Strategy int
+
+const (
+	// StrategyOrdered tries endpoints in the order they were listed: the first healthy one is used.
+	StrategyOrdered Strategy = iota
+	// StrategyRoundRobin starts every dial from the endpoint next to the one used by previous dial.
+	StrategyRoundRobin
+	// StrategyRandom tries endpoints in random order.
+	StrategyRandom
+)
+
+// FailoverPolicy sets the way client chooses among endpoints passed to DialEndpoints.
+type FailoverPolicy struct {
+	// Strategy sets order of endpoints to try. Default is StrategyOrdered.
+	Strategy Strategy
+
+	// RetryAfter is the time endpoint is marked unhealthy after failed dial or broken connection.
+	// Unhealthy endpoints are tried only after healthy ones. Default value (in case 0) is 30 seconds.
+	RetryAfter time.Duration
+}
+
+// EndpointStatus holds health of one endpoint.
+type EndpointStatus struct {
+	Addr string
+
+	// Healthy is false while endpoint is skipped after failure.
+	Healthy bool
+
+	// Failures is the number of failures in a row, FailedAt is the time of the last one.
+	Failures int
+	FailedAt time.Time
+}
+
+// endpoints holds list of server addresses client dials to and their health.
+type endpoints struct {
+	policy FailoverPolicy
+
+	mu   sync.Mutex
+	list []EndpointStatus
+	// next is the index round-robin strategy starts from.
+	next int
+}
+
+func newEndpoints(addrs []string, policy FailoverPolicy) *endpoints {
+	if policy.RetryAfter <= 0 {
+		policy.RetryAfter = defaultRetryAfter
+	}
+
+	list := make([]EndpointStatus, len(addrs))
+	for i, addr := range addrs {
+		list[i] = EndpointStatus{Addr: addr, Healthy: true, Failures: 0, FailedAt: time.Time{}}
+	}
+
+	return &endpoints{policy: policy, mu: sync.Mutex{}, list: list, next: 0}
+}
+
+// order returns addresses to try by strategy: healthy ones first, then unhealthy ones
+// starting from the one that failed earliest.
+func (e *endpoints) order() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	indexes := make([]int, len(e.list))
+
+	for i := range e.list {
+		indexes[i] = i
+	}
+
+	switch e.policy.Strategy {
+	case StrategyRoundRobin:
+		for i := range indexes {
+			indexes[i] = (e.next + i) % len(e.list)
+		}
+	case StrategyRandom:
+		rand.Shuffle(len(indexes), func(i, j int) { indexes[i], indexes[j] = indexes[j], indexes[i] }) //nolint:gosec // It's not for security
+	case StrategyOrdered:
+	}
+
+	for _, i := range indexes {
+		// Endpoint becomes healthy again once RetryAfter passed.
+		if !e.list[i].Healthy && !now.Before(e.list[i].FailedAt.Add(e.policy.RetryAfter)) {
+			e.list[i].Healthy = true
+		}
+	}
+
+	sort.SliceStable(indexes, func(a, b int) bool {
+		first, second := e.list[indexes[a]], e.list[indexes[b]]
+		if first.Healthy != second.Healthy {
+			return first.Healthy
+		}
+
+		return !first.Healthy && first.FailedAt.Before(second.FailedAt)
+	})
+
+	addrs := make([]string, len(indexes))
+	for i, index := range indexes {
+		addrs[i] = e.list[index].Addr
+	}
+
+	return addrs
+}
+
+// markFailed makes endpoint addr unhealthy for RetryAfter.
+func (e *endpoints) markFailed(addr string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for i := range e.list {
+		if e.list[i].Addr == addr {
+			e.list[i].Healthy = false
+			e.list[i].Failures++
+			e.list[i].FailedAt = time.Now()
+		}
+	}
+}
+
+// markHealthy resets failures of endpoint addr and makes the next one first for round-robin strategy.
+func (e *endpoints) markHealthy(addr string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for i := range e.list {
+		if e.list[i].Addr == addr {
+			e.list[i].Healthy = true
+			e.list[i].Failures = 0
+			e.next = (i + 1) % len(e.list)
+		}
+	}
+}
+
+// status returns copy of endpoints health.
+func (e *endpoints) status() []EndpointStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]EndpointStatus(nil), e.list...)
+}
+
+// markFailed marks endpoint client is connected to unhealthy after connection was broken.
+func (c *Client) markFailed() {
+	c.mu.RLock()
+	list, addr := c.endpoints, c.addr
+	c.mu.RUnlock()
+
+	if list != nil {
+		list.markFailed(addr)
+	}
+}
+
+// Endpoint returns address of the server client is connected (or was connected last time) to.
+func (c *Client) Endpoint() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.addr
+}
+
+// Endpoints returns health of endpoints client dialed to last time.
+func (c *Client) Endpoints() []EndpointStatus {
+	c.mu.RLock()
+	e := c.endpoints
+	c.mu.RUnlock()
+
+	if e == nil {
+		return nil
+	}
+
+	return e.status()
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unusedAddr returns address nobody listens on.
+func unusedAddr(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	return addr
+}
+
+func TestFailover(t *testing.T) {
+	first, _ := newTestServer(t, "127.0.0.1:0")
+	second, connections := newTestServer(t, "127.0.0.1:0")
+	dead := unusedAddr(t)
+	firstAddr, secondAddr := first.Addrs()[0].String(), second.Addrs()[0].String()
+
+	c := New(&Config{
+		SuppressErrors: true,
+		Reconnect:      &ReconnectPolicy{BaseBackoff: time.Millisecond * 20, MaxBackoff: time.Millisecond * 50},
+	})
+	require.NoError(t, c.DialEndpoints([]string{dead, firstAddr, secondAddr}, testTLSConfig))
+	assert.Equal(t, 0, waitEvent(t, c, StateConnected).Attempt)
+	assert.Equal(t, firstAddr, c.Endpoint())
+
+	status := c.Endpoints()
+	require.Len(t, status, 3)
+	assert.False(t, status[0].Healthy)
+	assert.Equal(t, 1, status[0].Failures)
+	assert.True(t, status[1].Healthy)
+
+	// Client fails over to the next server once connection is broken.
+	require.NoError(t, first.Stop())
+	assert.Greater(t, waitEvent(t, c, StateConnected).Attempt, 0)
+	assert.Equal(t, secondAddr, c.Endpoint())
+
+	_, err := (<-connections).SendString("one")
+	require.NoError(t, err)
+
+	message, err := c.GetMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "one", string(message.Bytes()))
+
+	status = c.Endpoints()
+	assert.False(t, status[1].Healthy)
+	assert.True(t, status[2].Healthy)
+
+	require.NoError(t, c.Close())
+	require.NoError(t, second.Stop())
+
+	// Client returns error of the last endpoint if none is available.
+	assert.ErrorContains(t, c.DialEndpoints([]string{dead, firstAddr}, testTLSConfig), firstAddr)
+	assert.ErrorIs(t, c.DialEndpoints(nil, testTLSConfig), ErrNoEndpoints)
+}
+
+func TestFailoverStrategies(t *testing.T) {
+	list := newEndpoints([]string{"a", "b", "c"}, FailoverPolicy{Strategy: StrategyRoundRobin, RetryAfter: time.Millisecond * 50})
+
+	assert.Equal(t, []string{"a", "b", "c"}, list.order())
+	list.markHealthy("a")
+	assert.Equal(t, []string{"b", "c", "a"}, list.order())
+
+	// Failed endpoints go last, the one that failed earlier goes first of them.
+	list.markFailed("c")
+	list.markFailed("b")
+	assert.Equal(t, []string{"a", "c", "b"}, list.order())
+
+	// Endpoints are healthy again after RetryAfter.
+	time.Sleep(time.Millisecond * 60)
+	assert.Equal(t, []string{"b", "c", "a"}, list.order())
+
+	ordered := newEndpoints([]string{"a", "b"}, FailoverPolicy{})
+	ordered.markHealthy("a")
+	assert.Equal(t, []string{"a", "b"}, ordered.order())
+
+	random := newEndpoints([]string{"a", "b", "c"}, FailoverPolicy{Strategy: StrategyRandom})
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, random.order())
+}
@@ -8,12 +8,18 @@ import (
 )
 
 // New creates new Client with specified config or default parameters.
+// Config is not validated here: call Config.Validate first, otherwise invalid TLSPolicy
+// is reported by the first dial.
 func New(conf *Config) *Client {
 	client := new(Client)
 	client.errChan = make(chan error, 3) //nolint:gomnd // false alarm
 	client.ClientDoneChan = make(chan bool)
 	client.messageChan = make(chan *conn.Message, 10) //nolint:gomnd // false alarm
+	client.events = make(chan Event, eventsBufferSize)
 	client.mu = &sync.RWMutex{}
+	client.done = make(chan struct{})
+	client.isClosed = true
+	close(client.done)
 	client.ver = semver.Ver{ //nolint:exhaustruct // false alarm
 		Major:       3, //nolint:gomnd // false alarm
 		Minor:       2, //nolint:gomnd // false alarm
@@ -33,6 +39,20 @@ func New(conf *Config) *Client {
 		conf.MessageTerminator = '\n'
 	}
 
+	// Default framing is the terminator-based one.
+	if conf.Framer == nil {
+		delimiter := conf.MessageDelimiter
+		if len(delimiter) == 0 {
+			delimiter = []byte{conf.MessageTerminator}
+		}
+
+		if conf.MessageEscaping {
+			conf.Framer = conn.NewEscapingDelimiterFramer(delimiter)
+		} else {
+			conf.Framer = conn.NewDelimiterFramer(delimiter)
+		}
+	}
+
 	// Default buffer is 128 B.
 	if conf.BufferSize == 0 {
 		conf.BufferSize = 128
@@ -44,5 +64,9 @@ func New(conf *Config) *Client {
 
 	client.conf = conf
 
+	if conf.Outbox != nil {
+		client.outbox = newOutbox(*conf.Outbox)
+	}
+
 	return client
 }
@@ -0,0 +1,257 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lazybark/go-tls-server/conn"
+)
+
+// defaultOutboxSize is the outbox capacity if OutboxPolicy.Size is 0.
+const defaultOutboxSize = 100
+
+// defaultFlushTimeout limits writing of one queued message if OutboxPolicy.FlushTimeout is 0.
+const defaultFlushTimeout = 10 * time.Second
+
+// ErrOutboxFull is returned when message can't be queued because outbox is full (see OverflowDropNewest).
+var ErrOutboxFull = errors.New("outbox is full")
+
+// Overflow is the way outbox treats new message when it's full.
+type Overflow int
+
+const (
+	// OverflowDropOldest removes the oldest queued message to make room for the new one.
+	OverflowDropOldest Overflow = iota
+	// OverflowDropNewest rejects the new message with ErrOutboxFull.
+	OverflowDropNewest
+	// OverflowBlock makes sender wait until there is room, client is closed or context of SendContext is done.
+	// Client that was never connected is closed, so sender doesn't wait.
+	OverflowBlock
+)
+
+// OutboxPolicy makes client queue outgoing messages while it's disconnected (before dial or during reconnect)
+// and send them in order once connection is made.
+type OutboxPolicy struct {
+	// Size limits number of queued messages. Default value (in case 0) is 100.
+	Size int
+
+	// Overflow sets the way new message is treated when outbox is full.
+	Overflow Overflow
+
+	// TTL drops messages that were queued for longer. Deadline of SendContext context expires message too.
+	// 0 means messages don't expire.
+	TTL time.Duration
+
+	// FlushTimeout limits writing of one queued message once connection is made. Connection that can't take
+	// message in time is closed, message stays in queue for the next one. Default value (in case 0) is 10 seconds.
+	FlushTimeout time.Duration
+}
+
+// outboxMessage is the message that waits for connection.
+type outboxMessage struct {
+	payload   []byte
+	expiresAt time.Time
+}
+
+// expired returns true if message is not needed anymore at moment now.
+func (m outboxMessage) expired(now time.Time) bool {
+	return !m.expiresAt.IsZero() && !now.Before(m.expiresAt)
+}
+
+// outbox queues messages while client is disconnected.
+type outbox struct {
+	policy OutboxPolicy
+
+	mu sync.Mutex
+
+	// flushMu makes flushes run one by one. Senders don't wait for it.
+	flushMu sync.Mutex
+
+	// online is true when connection is made and queue was flushed, so messages can be sent right away.
+	online bool
+
+	queue []outboxMessage
+
+	// changed is closed and renewed every time queue gets shorter, so blocked senders can check it again.
+	changed chan struct{}
+
+	dropped int
+	expired int
+}
+
+// newOutbox returns outbox with policy defaults applied.
+func newOutbox(policy OutboxPolicy) *outbox {
+	if policy.Size <= 0 {
+		policy.Size = defaultOutboxSize
+	}
+
+	if policy.FlushTimeout <= 0 {
+		policy.FlushTimeout = defaultFlushTimeout
+	}
+
+	return &outbox{policy: policy, changed: make(chan struct{})} //nolint:exhaustruct // It's OK
+}
+
+// setOffline makes outbox queue new messages.
+func (o *outbox) setOffline() {
+	o.mu.Lock()
+	o.online = false
+	o.mu.Unlock()
+}
+
+// enqueue queues payload unless outbox is online. It returns false if payload should be sent right away.
+// Blocking overflow waits until there is room, ctx is done or done is closed.
+func (o *outbox) enqueue(ctx context.Context, done <-chan struct{}, payload []byte) (bool, error) {
+	message := outboxMessage{payload: append([]byte(nil), payload...)} //nolint:exhaustruct // It's OK
+	if o.policy.TTL > 0 {
+		message.expiresAt = time.Now().Add(o.policy.TTL)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && (message.expiresAt.IsZero() || deadline.Before(message.expiresAt)) {
+		message.expiresAt = deadline
+	}
+
+	for {
+		o.mu.Lock()
+
+		if o.online {
+			o.mu.Unlock()
+
+			return false, nil
+		}
+
+		o.dropExpired(time.Now())
+
+		if len(o.queue) < o.policy.Size {
+			o.queue = append(o.queue, message)
+			o.mu.Unlock()
+
+			return true, nil
+		}
+
+		switch o.policy.Overflow {
+		case OverflowDropOldest:
+			o.queue = append(o.queue[1:], message)
+			o.dropped++
+			o.mu.Unlock()
+
+			return true, nil
+		case OverflowDropNewest:
+			o.dropped++
+			o.mu.Unlock()
+
+			return true, ErrOutboxFull
+		case OverflowBlock:
+		}
+
+		changed := o.changed
+		o.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return true, fmt.Errorf("[outbox] %w", &conn.CanceledError{Op: "enqueue", Err: ctx.Err()})
+		case <-done:
+			return true, conn.ErrConnectionClosed
+		}
+	}
+}
+
+// dropExpired removes expired messages from queue. Caller must hold o.mu.
+func (o *outbox) dropExpired(now time.Time) {
+	kept := o.queue[:0]
+
+	for _, message := range o.queue {
+		if message.expired(now) {
+			o.expired++
+
+			continue
+		}
+
+		kept = append(kept, message)
+	}
+
+	if len(kept) < len(o.queue) {
+		o.queue = kept
+		o.notify()
+	}
+}
+
+// notify wakes up blocked senders. Caller must hold o.mu.
+func (o *outbox) notify() {
+	close(o.changed)
+	o.changed = make(chan struct{})
+}
+
+// flush sends queued messages into cn in order they came and makes outbox online.
+// Lock is not held while message is written, so slow server doesn't block senders: outbox stays offline
+// during flush and new messages are queued behind old ones. Every message is written within FlushTimeout,
+// connection that can't take it is aborted. If sending fails, message stays in queue and outbox stays offline
+// until next flush.
+func (o *outbox) flush(cn *conn.Connection) error {
+	o.flushMu.Lock()
+	defer o.flushMu.Unlock()
+
+	for {
+		o.mu.Lock()
+		o.dropExpired(time.Now())
+
+		if len(o.queue) == 0 {
+			o.online = true
+			o.mu.Unlock()
+
+			return nil
+		}
+
+		message := o.queue[0]
+		o.queue = o.queue[1:]
+		o.mu.Unlock()
+
+		err := o.send(cn, message.payload)
+
+		o.mu.Lock()
+
+		if err != nil {
+			// Message goes back to the head of queue, unless new messages took its room.
+			if len(o.queue) < o.policy.Size {
+				o.queue = append([]outboxMessage{message}, o.queue...)
+			} else {
+				o.dropped++
+			}
+
+			o.mu.Unlock()
+
+			return err
+		}
+
+		o.notify()
+		o.mu.Unlock()
+	}
+}
+
+// send writes payload into cn within FlushTimeout. Message could be written partially, so cn is aborted
+// if write was stopped.
+func (o *outbox) send(cn *conn.Connection, payload []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), o.policy.FlushTimeout)
+	defer cancel()
+
+	_, err := cn.SendContext(ctx, payload)
+
+	var canceled *conn.CanceledError
+	if errors.As(err, &canceled) {
+		_ = cn.Abort()
+	}
+
+	return err
+}
+
+// stats returns queue depth and numbers of dropped and expired messages.
+func (o *outbox) stats() (int, int, int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return len(o.queue), o.dropped, o.expired
+}
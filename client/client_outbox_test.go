@@ -0,0 +1,176 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/lazybark/go-tls-server/conn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxFlush(t *testing.T) {
+	srv, connections := newTestServer(t, "127.0.0.1:0")
+	addr := srv.Addrs()[0].String()
+
+	c := New(&Config{
+		SuppressErrors: true,
+		Reconnect:      &ReconnectPolicy{BaseBackoff: time.Millisecond * 20, MaxBackoff: time.Millisecond * 50},
+		Outbox:         &OutboxPolicy{Size: 2, Overflow: OverflowDropOldest},
+	})
+
+	// Messages sent before dial wait for connection.
+	count, err := c.SendString("zero")
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, 1, c.Snapshot().OutboxDepth)
+
+	require.NoError(t, c.DialWithConfig(addr, testTLSConfig))
+
+	message, err := (<-connections).GetMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "zero", string(message.Bytes()))
+	assert.Equal(t, 0, c.Snapshot().OutboxDepth)
+
+	// Messages sent during reconnect are flushed in order, the oldest one is dropped.
+	require.NoError(t, srv.Stop())
+	waitEvent(t, c, StateDisconnected)
+
+	for _, s := range []string{"one", "two", "three"} {
+		_, err = c.SendString(s)
+		require.NoError(t, err)
+	}
+
+	snapshot := c.Snapshot()
+	assert.Equal(t, 2, snapshot.OutboxDepth)
+	assert.Equal(t, 1, snapshot.OutboxDropped)
+
+	srv, connections = newTestServer(t, addr)
+	connection := <-connections
+
+	for _, expected := range []string{"two", "three"} {
+		message, err = connection.GetMessage()
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(message.Bytes()))
+	}
+
+	// Connected client sends right away.
+	count, err = c.SendString("four")
+	require.NoError(t, err)
+	assert.Equal(t, len("four\n"), count)
+
+	message, err = connection.GetMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "four", string(message.Bytes()))
+
+	require.NoError(t, c.Close())
+	require.NoError(t, srv.Stop())
+}
+
+func TestOutboxOverflow(t *testing.T) {
+	newest := New(&Config{Outbox: &OutboxPolicy{Size: 1, Overflow: OverflowDropNewest}})
+
+	_, err := newest.SendString("one")
+	require.NoError(t, err)
+
+	_, err = newest.SendString("two")
+	assert.True(t, errors.Is(err, ErrOutboxFull))
+	assert.Equal(t, 1, newest.Snapshot().OutboxDropped)
+
+	// Client that was never connected doesn't block.
+	block := New(&Config{Outbox: &OutboxPolicy{Size: 1, Overflow: OverflowBlock}})
+
+	_, err = block.SendString("one")
+	require.NoError(t, err)
+
+	_, err = block.SendString("two")
+	assert.True(t, errors.Is(err, conn.ErrConnectionClosed))
+
+	// Blocked sender waits for ctx.
+	block.mu.Lock()
+	block.done = make(chan struct{})
+	block.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	_, err = block.SendContext(ctx, []byte("two"))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, 1, block.Snapshot().OutboxDepth)
+}
+
+func TestOutboxExpiry(t *testing.T) {
+	c := New(&Config{Outbox: &OutboxPolicy{TTL: time.Millisecond * 20}})
+
+	_, err := c.SendString("one")
+	require.NoError(t, err)
+
+	// Deadline of context expires message earlier than TTL.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	_, err = c.SendContext(ctx, []byte("two"))
+	require.NoError(t, err)
+
+	time.Sleep(time.Millisecond * 5)
+
+	_, err = c.SendString("three")
+	require.NoError(t, err)
+
+	snapshot := c.Snapshot()
+	assert.Equal(t, 2, snapshot.OutboxDepth)
+	assert.Equal(t, 1, snapshot.OutboxExpired)
+
+	time.Sleep(time.Millisecond * 30)
+
+	c.outbox.mu.Lock()
+	c.outbox.dropExpired(time.Now())
+	c.outbox.mu.Unlock()
+
+	snapshot = c.Snapshot()
+	assert.Equal(t, 0, snapshot.OutboxDepth)
+	assert.Equal(t, 3, snapshot.OutboxExpired)
+}
+
+func TestOutboxFlushStuckConnection(t *testing.T) {
+	local, remote := net.Pipe()
+	defer remote.Close()
+
+	cn, err := conn.NewConnection(local.RemoteAddr(), local, '\n')
+	require.NoError(t, err)
+
+	o := newOutbox(OutboxPolicy{FlushTimeout: time.Millisecond * 100})
+
+	queued, err := o.enqueue(context.Background(), nil, []byte("one"))
+	require.NoError(t, err)
+	require.True(t, queued)
+
+	// Nobody reads the pipe, so flush is stuck until FlushTimeout.
+	flushErr := make(chan error, 1)
+
+	go func() { flushErr <- o.flush(cn) }()
+
+	time.Sleep(time.Millisecond * 20)
+
+	// Senders are not blocked by flush, new message is queued behind the old one.
+	started := time.Now()
+	queued, err = o.enqueue(context.Background(), nil, []byte("two"))
+	require.NoError(t, err)
+	assert.True(t, queued)
+	assert.Less(t, time.Since(started), time.Millisecond*50)
+
+	var canceled *conn.CanceledError
+	assert.True(t, errors.As(<-flushErr, &canceled))
+	assert.True(t, cn.Closed())
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	require.Len(t, o.queue, 2)
+	assert.Equal(t, "one", string(o.queue[0].payload))
+	assert.Equal(t, "two", string(o.queue[1].payload))
+	assert.False(t, o.online)
+}
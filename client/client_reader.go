@@ -6,25 +6,50 @@ import (
 	"github.com/lazybark/go-tls-server/conn"
 )
 
-// Reader infinitely reads messages from opened connection.
-func (c *Client) reader() {
+// Reader infinitely reads messages from opened connection cn. If connection is broken while client is not closed,
+// it starts reconnecting (see Config.Reconnect).
+func (c *Client) reader(cn *conn.Connection, done chan struct{}) {
 	for {
-		if c.conn.Closed() || c.Closed() {
+		if cn.Closed() || c.Closed() {
 			return
 		}
 
-		bytes, bytesCount, err := c.conn.ReadWithContext(c.conf.BufferSize, c.conf.MaxMessageSize, c.conf.MessageTerminator)
+		bytes, _, err := cn.ReadMessage(c.conf.BufferSize, c.conf.MaxMessageSize)
 		if err != nil {
+			// Reader is gone, so TLS stream must be closed here.
+			_ = cn.Abort()
+
+			if c.Closed() {
+				return
+			}
+
 			if !c.conf.SuppressErrors {
 				c.errChan <- fmt.Errorf("[Reader] error reading from %s -> %w", c.host, err)
 			}
 
+			c.markFailed()
+
+			if c.outbox != nil {
+				c.outbox.setOffline()
+			}
+
+			c.emit(StateDisconnected, 0, err)
+
+			if c.conf.Reconnect != nil {
+				c.reconnect(done)
+			}
+
 			return
 		}
 
-		// Check in case we read 0 bytes.
-		if bytesCount > 0 {
-			c.messageChan <- conn.NewMessage(c.conn, bytesCount, bytes)
+		// Nil means reading was stopped without a message. Message may also come from bytes
+		// left after previous one, in that case 0 bytes were read.
+		if bytes != nil {
+			message := conn.NewMessage(cn, len(bytes), bytes)
+			// Replies go straight to requests waiting for them.
+			if !cn.Resolve(message) {
+				c.messageChan <- message
+			}
 		}
 	}
 }
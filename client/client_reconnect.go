@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/lazybark/go-tls-server/conn"
+)
+
+const (
+	// defaultBaseBackoff is the delay before the second reconnect attempt if ReconnectPolicy.BaseBackoff is 0.
+	defaultBaseBackoff = 100 * time.Millisecond
+	// defaultMaxBackoff limits delay between reconnect attempts if ReconnectPolicy.MaxBackoff is 0.
+	defaultMaxBackoff = 30 * time.Second
+)
+
+// ReconnectPolicy makes client dial again once connection is broken.
+type ReconnectPolicy struct {
+	// MaxAttempts limits number of reconnect attempts in a row. 0 means client tries until it's closed.
+	MaxAttempts int
+
+	// BaseBackoff is the delay after the first failed attempt, it doubles after every next one.
+	// First attempt is made right away. Default value (in case 0) is 100 ms.
+	BaseBackoff time.Duration
+
+	// MaxBackoff limits delay between attempts. Default value (in case 0) is 30 seconds.
+	MaxBackoff time.Duration
+
+	// Jitter randomly shortens every delay by up to this fraction (0 to 1), so many clients
+	// don't reconnect at the same moment.
+	Jitter float64
+}
+
+// backoff returns delay after failed attempt number attempt (starting from 1).
+func (p *ReconnectPolicy) backoff(attempt int) time.Duration {
+	base, limit := p.BaseBackoff, p.MaxBackoff
+	if base <= 0 {
+		base = defaultBaseBackoff
+	}
+
+	if limit <= 0 {
+		limit = defaultMaxBackoff
+	}
+
+	delay := base
+	for i := 1; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+
+	if delay > limit {
+		delay = limit
+	}
+
+	if p.Jitter > 0 {
+		delay -= time.Duration(float64(delay) * p.Jitter * rand.Float64()) //nolint:gosec // It's not for security
+	}
+
+	return delay
+}
+
+// reconnect dials to the last servers again until connection is made, attempts are over or client is closed.
+// Client that gave up is closed with error.
+func (c *Client) reconnect(done chan struct{}) {
+	policy := c.conf.Reconnect
+
+	for attempt := 1; policy.MaxAttempts == 0 || attempt <= policy.MaxAttempts; attempt++ {
+		c.emit(StateConnecting, attempt, nil)
+
+		err := c.redial(done)
+
+		switch {
+		case err == nil:
+			c.emit(StateConnected, attempt, nil)
+			c.flushOutbox()
+
+			return
+		case errors.Is(err, conn.ErrConnectionClosed):
+			// Client was closed while dialing.
+			return
+		}
+
+		c.emit(StateDisconnected, attempt, c.FormatError(fmt.Errorf("[reconnect] %w", err)))
+
+		select {
+		case <-time.After(policy.backoff(attempt)):
+		case <-done:
+			return
+		}
+	}
+
+	c.emit(StateGaveUp, policy.MaxAttempts, nil)
+	_ = c.close(true)
+}
+
+// redial dials to the last servers again and makes new connection the current one.
+func (c *Client) redial(done chan struct{}) error {
+	c.mu.RLock()
+	list, config := c.endpoints, c.tlsConfig
+	c.mu.RUnlock()
+
+	// Closing client stops dialing.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	netConn, addr, err := c.connectAny(ctx, list, config)
+	if err != nil && isDone(done) {
+		return conn.ErrConnectionClosed
+	} else if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.addr = addr
+	c.mu.Unlock()
+
+	c.host = addr
+
+	return c.attach(netConn, done)
+}
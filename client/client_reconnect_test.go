@@ -0,0 +1,133 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitEvent reads events of c until event with state comes.
+func waitEvent(t *testing.T, c *Client, state State) Event {
+	t.Helper()
+
+	timeout := time.After(time.Second * 5)
+
+	for {
+		select {
+		case event := <-c.Events():
+			if event.State == state {
+				return event
+			}
+		case <-timeout:
+			require.FailNow(t, "no event", state.String())
+		}
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	srv, connections := newTestServer(t, "127.0.0.1:0")
+	addr := srv.Addrs()[0].String()
+
+	c := New(&Config{
+		SuppressErrors: true,
+		Reconnect:      &ReconnectPolicy{BaseBackoff: time.Millisecond * 20, MaxBackoff: time.Millisecond * 100, Jitter: 0.5},
+	})
+	require.NoError(t, c.DialWithConfig(addr, testTLSConfig))
+	assert.Equal(t, 0, waitEvent(t, c, StateConnected).Attempt)
+
+	_, err := (<-connections).SendString("one")
+	require.NoError(t, err)
+
+	message, err := c.GetMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "one", string(message.Bytes()))
+
+	// Server goes away and comes back on the same address.
+	require.NoError(t, srv.Stop())
+	assert.Error(t, waitEvent(t, c, StateDisconnected).Err)
+	assert.Error(t, waitEvent(t, c, StateDisconnected).Err)
+	assert.False(t, c.Closed())
+
+	srv, connections = newTestServer(t, addr)
+
+	assert.Greater(t, waitEvent(t, c, StateConnected).Attempt, 0)
+
+	// Messages come into the same channel.
+	_, err = (<-connections).SendString("two")
+	require.NoError(t, err)
+
+	message, err = c.GetMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "two", string(message.Bytes()))
+
+	_, err = c.SendString("ping")
+	require.NoError(t, err)
+
+	require.NoError(t, c.Close())
+	require.NoError(t, srv.Stop())
+}
+
+func TestReconnectClosesBrokenConnection(t *testing.T) {
+	srv, connections := newTestServer(t, "127.0.0.1:0")
+
+	c := New(&Config{
+		SuppressErrors: true,
+		MaxMessageSize: 8,
+		Reconnect:      &ReconnectPolicy{BaseBackoff: time.Millisecond * 20, MaxBackoff: time.Millisecond * 100},
+	})
+	require.NoError(t, c.DialWithConfig(srv.Addrs()[0].String(), testTLSConfig))
+	waitEvent(t, c, StateConnected)
+
+	first := <-connections
+
+	// Too long message breaks connection, client reconnects and closes the broken one.
+	_, err := first.SendString("too long message")
+	require.NoError(t, err)
+
+	waitEvent(t, c, StateDisconnected)
+	waitEvent(t, c, StateConnected)
+	<-connections
+
+	assert.Eventually(t, first.Closed, time.Second*5, time.Millisecond*10)
+
+	require.NoError(t, c.Close())
+	require.NoError(t, srv.Stop())
+}
+
+func TestReconnectGiveUp(t *testing.T) {
+	srv, _ := newTestServer(t, "127.0.0.1:0")
+
+	c := New(&Config{
+		SuppressErrors: true,
+		Reconnect:      &ReconnectPolicy{MaxAttempts: 2, BaseBackoff: time.Millisecond * 10},
+	})
+	require.NoError(t, c.DialWithConfig(srv.Addrs()[0].String(), testTLSConfig))
+
+	require.NoError(t, srv.Stop())
+
+	assert.Equal(t, 2, waitEvent(t, c, StateGaveUp).Attempt)
+	assert.True(t, c.Closed())
+	assert.True(t, c.ClosedWithError())
+}
+
+func TestReconnectBackoff(t *testing.T) {
+	policy := &ReconnectPolicy{BaseBackoff: time.Millisecond * 100, MaxBackoff: time.Millisecond * 500}
+
+	assert.Equal(t, time.Millisecond*100, policy.backoff(1))
+	assert.Equal(t, time.Millisecond*200, policy.backoff(2))
+	assert.Equal(t, time.Millisecond*400, policy.backoff(3))
+	assert.Equal(t, time.Millisecond*500, policy.backoff(4))
+	assert.Equal(t, time.Millisecond*500, policy.backoff(100))
+
+	policy.Jitter = 0.5
+
+	for i := 0; i < 100; i++ {
+		delay := policy.backoff(2)
+		assert.GreaterOrEqual(t, delay, time.Millisecond*100)
+		assert.LessOrEqual(t, delay, time.Millisecond*200)
+	}
+
+	assert.Equal(t, defaultBaseBackoff, (&ReconnectPolicy{}).backoff(1))
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/lazybark/go-tls-server/conn"
+	"github.com/lazybark/go-tls-server/handler"
+)
+
+// responseWriter writes into client connection.
+type responseWriter struct {
+	client  *Client
+	message *conn.Message
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.message.IsRequest() {
+		return w.client.Reply(w.message, b)
+	}
+
+	return w.client.SendByte(b)
+}
+
+func (w *responseWriter) WriteString(s string) (int, error) { return w.Write([]byte(s)) }
+
+func (w *responseWriter) Conn() *conn.Connection { return w.message.Conn() }
+
+// Serve calls handler for every message received from server, one by one in order they came.
+// It's the client side of server.Server.Serve, so the same handlers and routers (see handler package)
+// can be used by both.
+// Panics in handler are recovered and sent into error channel.
+//
+// Client must be connected. Serve blocks until client is closed and then returns conn.ErrConnectionClosed.
+func (c *Client) Serve(h handler.Handler) error {
+	for {
+		c.mu.RLock()
+		done := c.done
+		c.mu.RUnlock()
+
+		select {
+		case message := <-c.messageChan:
+			c.handle(h, message)
+		case <-done:
+			return conn.ErrConnectionClosed
+		}
+	}
+}
+
+// handle calls handler and recovers from its panic.
+func (c *Client) handle(h handler.Handler, message *conn.Message) {
+	defer func() {
+		if r := recover(); r != nil {
+			if !c.conf.SuppressErrors {
+				c.errChan <- c.FormatError(fmt.Errorf("[Serve] %w: %v", handler.ErrHandlerPanic, r))
+			}
+		}
+	}()
+
+	h.ServeMessage(&responseWriter{client: c, message: message}, message)
+}
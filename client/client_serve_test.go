@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lazybark/go-tls-server/conn"
+	"github.com/lazybark/go-tls-server/handler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServe(t *testing.T) {
+	srv, connections := newTestServer(t, "127.0.0.1:0")
+
+	c := New(nil)
+	require.NoError(t, c.DialWithConfig(srv.Addrs()[0].String(), testTLSConfig))
+
+	connection := <-connections
+
+	// Server side reads client answers.
+	go func() {
+		for {
+			message, err := connection.GetMessage()
+			if err != nil {
+				return
+			}
+
+			if message.IsRequest() {
+				_, _ = message.Reply(append([]byte("re: "), message.Bytes()...))
+
+				continue
+			}
+
+			_, _ = connection.SendString("server: " + string(message.Bytes()))
+		}
+	}()
+
+	answers := make(chan string, 1)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- c.Serve(handler.HandlerFunc(func(w handler.ResponseWriter, m *conn.Message) {
+			switch string(m.Bytes()) {
+			case "PING":
+				_, _ = w.WriteString("pong")
+			case "PANIC":
+				panic("handler failed")
+			default:
+				answers <- string(m.Bytes())
+			}
+		}))
+	}()
+
+	// Plain message is answered with plain one.
+	_, err := connection.SendString("PING")
+	require.NoError(t, err)
+
+	select {
+	case answer := <-answers:
+		assert.Equal(t, "server: pong", answer)
+	case <-time.After(time.Second * 5):
+		require.FailNow(t, "no answer")
+	}
+
+	// Request from server is answered with reply.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	reply, err := connection.Request(ctx, []byte("PING"))
+	require.NoError(t, err)
+	assert.Equal(t, "pong", string(reply.Bytes()))
+
+	// Panic is recovered and reported, client keeps serving.
+	_, err = connection.SendString("PANIC")
+	require.NoError(t, err)
+	assert.True(t, errors.Is(<-c.ErrChan(), handler.ErrHandlerPanic))
+
+	reply, err = connection.Request(ctx, []byte("PING"))
+	require.NoError(t, err)
+	assert.Equal(t, "pong", string(reply.Bytes()))
+
+	require.NoError(t, c.Close())
+	assert.True(t, errors.Is(<-serveErr, conn.ErrConnectionClosed))
+	require.NoError(t, srv.Stop())
+}
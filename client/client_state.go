@@ -0,0 +1,63 @@
+package client
+
+import (
+	"fmt"
+	"time"
+)
+
+// eventsBufferSize is the size of state events channel buffer.
+const eventsBufferSize = 16
+
+// State is the connection state of client.
+type State int
+
+const (
+	// StateConnecting means client dials to server, Event.Attempt holds number of reconnect attempt.
+	StateConnecting State = iota
+	// StateConnected means connection was made.
+	StateConnected
+	// StateDisconnected means connection was broken or reconnect attempt failed, Event.Err holds the reason.
+	StateDisconnected
+	// StateGaveUp means reconnect attempts are over and client is closed.
+	StateGaveUp
+)
+
+func (st State) String() string {
+	switch st {
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateDisconnected:
+		return "disconnected"
+	case StateGaveUp:
+		return "gave up"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(st))
+	}
+}
+
+// Event is the change of client connection state.
+type Event struct {
+	State State
+
+	// Attempt is the number of reconnect attempt, 0 for the first dial.
+	Attempt int
+
+	// Err is the reason of disconnection.
+	Err error
+
+	Time time.Time
+}
+
+// Events returns channel of connection state events. Events are dropped if channel buffer is full,
+// so slow reader doesn't stop client from reconnecting.
+func (c *Client) Events() <-chan Event { return c.events }
+
+// emit sends state event into events channel unless it's full.
+func (c *Client) emit(state State, attempt int, err error) {
+	select {
+	case c.events <- Event{State: state, Attempt: attempt, Err: err, Time: time.Now()}:
+	default:
+	}
+}
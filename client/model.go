@@ -1,6 +1,8 @@
 package client
 
 import (
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"sync"
 
@@ -8,6 +10,9 @@ import (
 	"github.com/lazybark/go-tls-server/conn"
 )
 
+// ErrInvalidCA is returned when no certificates can be parsed from CA PEM.
+var ErrInvalidCA = errors.New("unable to parse CA certificates")
+
 // Client is the TLS client managing one single connection & statistics.
 //
 // IMPORTANT: it has all stats & methods assigned to the Client struct itself, not to separate Connection struct
@@ -21,6 +26,18 @@ type Client struct {
 	// host is the remote server to connect.
 	host string
 
+	// addr is the endpoint client is connected to, endpoints and tlsConfig are the last ones
+	// client dialed with, they are used to reconnect.
+	addr      string
+	endpoints *endpoints
+	tlsConfig *tls.Config
+
+	// events is the channel of connection state events.
+	events chan Event
+
+	// outbox queues messages while client is disconnected, nil if Config.Outbox is not set.
+	outbox *outbox
+
 	// isClosed is true when there is no connection or the connection was broken.
 	isClosed bool
 
@@ -40,6 +57,9 @@ type Client struct {
 	// messageChan channel to notify external routine about new messages.
 	messageChan chan *conn.Message
 
+	// done is closed when client is closed. It's renewed on every dial.
+	done chan struct{}
+
 	// connCount holds total number of successful conections of the client.
 	connCount int
 
@@ -59,7 +79,15 @@ func (c *Client) ErrChan() <-chan error {
 }
 
 func (c *Client) Next() bool {
-	return !c.conn.Closed()
+	return !c.connection().Closed()
+}
+
+// connection returns current connection of client. It changes on every dial and reconnect.
+func (c *Client) connection() *conn.Connection {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.conn
 }
 
 // GetMessage returns new message or error. Code will be locked until new message appears
@@ -74,7 +102,47 @@ func (c *Client) GetMessage() (*conn.Message, error) {
 }
 
 // Stats returns number of bytes sent/receive + number of errors.
-func (c *Client) Stats() (int, int, int) { return c.conn.Stats() }
+func (c *Client) Stats() (int, int, int) { return c.connection().Stats() }
+
+// StatsSnapshot holds stats of client at some moment.
+type StatsSnapshot struct {
+	// Sent, Received and Errors are the same as returned by Stats.
+	Sent     int
+	Received int
+	Errors   int
+
+	// Connections is the number of successful connections, including reconnects.
+	Connections int
+
+	// OutboxDepth is the number of messages queued in outbox, OutboxDropped and OutboxExpired
+	// are numbers of messages that were dropped by overflow policy or expired before being sent.
+	OutboxDepth   int
+	OutboxDropped int
+	OutboxExpired int
+}
+
+// Snapshot returns current stats of client, including outbox queue depth.
+func (c *Client) Snapshot() StatsSnapshot {
+	var snapshot StatsSnapshot
+
+	c.mu.RLock()
+	cn := c.conn
+	snapshot.Connections = c.connCount
+	c.mu.RUnlock()
+
+	if cn != nil {
+		snapshot.Sent, snapshot.Received, snapshot.Errors = cn.Stats()
+	}
+
+	if c.outbox != nil {
+		snapshot.OutboxDepth, snapshot.OutboxDropped, snapshot.OutboxExpired = c.outbox.stats()
+	}
+
+	return snapshot
+}
+
+// TLSState returns negotiated TLS state of current connection or nil if connection is not a TLS one.
+func (c *Client) TLSState() *conn.TLSState { return c.connection().TLSState() }
 
 // Version returns app version.
 func (c *Client) Version() semver.Ver { return c.ver }
@@ -83,10 +151,15 @@ func (c *Client) Version() semver.Ver { return c.ver }
 func (c *Client) close(withError bool) error {
 	c.mu.Lock()
 	c.isClosedWithError = withError
+
+	if !c.isClosed {
+		close(c.done)
+	}
+
 	c.isClosed = true
 	c.mu.Unlock()
 
-	err := c.conn.Close()
+	err := c.connection().Close()
 	if err != nil {
 		return fmt.Errorf("[close] %w", err)
 	}
@@ -0,0 +1,490 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lazybark/go-tls-server/conn"
+)
+
+// ErrPoolDialed is returned when pool that is already dialed is dialed again.
+var ErrPoolDialed = errors.New("pool is already dialed")
+
+// defaultPoolSize is the number of connections in pool if PoolConfig.Size is 0.
+const defaultPoolSize = 4
+
+// PoolStrategy sets the way pool chooses connection for the next send.
+type PoolStrategy int
+
+const (
+	// PoolLeastBusy chooses connection with the fewest sends in progress, then the one that sent fewer bytes.
+	PoolLeastBusy PoolStrategy = iota
+	// PoolRoundRobin chooses connections one after another.
+	PoolRoundRobin
+)
+
+// PoolConfig sets pool of connections to the same server.
+type PoolConfig struct {
+	// Size is the number of connections. Default value (in case 0) is 4.
+	Size int
+
+	// Strategy sets the way connection for the next send is chosen. Default is PoolLeastBusy.
+	Strategy PoolStrategy
+
+	// Replace sets delays between attempts to replace broken connection. Pool tries until it's closed,
+	// MaxAttempts is ignored. Default: delays of empty ReconnectPolicy.
+	Replace *ReconnectPolicy
+
+	// Client is the config of every connection. Its Reconnect is ignored: pool replaces broken connections itself.
+	Client *Config
+}
+
+// Pool keeps several connections to the same server, spreads sends among them and merges incoming messages
+// into one channel. Broken connections are replaced by new ones.
+type Pool struct {
+	conf PoolConfig
+	// clientConf is the config shared by all members.
+	clientConf *Config
+
+	// addrs and tlsConfig are the ones pool dialed with, they are used to replace broken connections.
+	addrs     []string
+	tlsConfig *tls.Config
+
+	mu      *sync.RWMutex
+	members []*poolMember
+	// next is the member round-robin strategy chooses next.
+	next int
+
+	// retired holds stats of replaced members, so pool stats don't go back.
+	retired StatsSnapshot
+
+	messageChan chan *conn.Message
+	errChan     chan error
+
+	// done is closed when pool is closed.
+	done     chan struct{}
+	isClosed bool
+}
+
+// poolMember is one connection of pool.
+type poolMember struct {
+	client *Client
+	// inFlight is the number of sends in progress.
+	inFlight int
+}
+
+// NewPool creates new Pool with specified config or default parameters.
+// Pool must be dialed before use. Client config is not validated here, call Config.Validate first.
+func NewPool(conf *PoolConfig) *Pool {
+	if conf == nil {
+		conf = new(PoolConfig)
+	}
+
+	pool := new(Pool)
+	pool.conf = *conf
+
+	if pool.conf.Size <= 0 {
+		pool.conf.Size = defaultPoolSize
+	}
+
+	if pool.conf.Replace == nil {
+		pool.conf.Replace = new(ReconnectPolicy)
+	}
+
+	clientConf := new(Config)
+	if conf.Client != nil {
+		*clientConf = *conf.Client
+	} else {
+		clientConf.DropOldStats = true
+	}
+
+	clientConf.Reconnect = nil
+	pool.clientConf = clientConf
+
+	pool.mu = &sync.RWMutex{}
+	pool.messageChan = make(chan *conn.Message, 10) //nolint:gomnd // false alarm
+	pool.errChan = make(chan error, 3)              //nolint:gomnd // false alarm
+	pool.done = make(chan struct{})
+	pool.isClosed = true
+
+	return pool
+}
+
+// DialWithConfig makes all connections of pool to server at addr using copy of config (see Client.DialWithConfig).
+func (p *Pool) DialWithConfig(addr string, config *tls.Config) error {
+	return p.DialEndpoints([]string{addr}, config)
+}
+
+// DialEndpoints makes all connections of pool to servers at addrs (see Client.DialEndpoints).
+// If any connection can't be made, the ones already made are closed.
+// Invalid TLSPolicy of client config is rejected before connecting (see Config.Validate).
+func (p *Pool) DialEndpoints(addrs []string, config *tls.Config) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.isClosed {
+		return fmt.Errorf("[Pool] dial: %w", ErrPoolDialed)
+	}
+
+	if err := p.clientConf.Validate(); err != nil {
+		return fmt.Errorf("[Pool] dial: %w", err)
+	}
+
+	members := make([]*poolMember, 0, p.conf.Size)
+
+	for i := 0; i < p.conf.Size; i++ {
+		member := New(p.clientConf)
+
+		err := member.DialEndpoints(addrs, config)
+		if err != nil {
+			for _, m := range members {
+				_ = m.client.Close()
+			}
+
+			return fmt.Errorf("[Pool] dial: %w", err)
+		}
+
+		members = append(members, &poolMember{client: member, inFlight: 0})
+	}
+
+	p.addrs = addrs
+	p.tlsConfig = config
+	p.members = members
+	p.done = make(chan struct{})
+	p.isClosed = false
+
+	for slot, member := range members {
+		go p.forward(member.client, p.done)
+		go p.watch(slot, member.client, p.done)
+	}
+
+	return nil
+}
+
+// forward sends messages and errors of member into pool channels until member or pool is closed.
+func (p *Pool) forward(member *Client, done chan struct{}) {
+	member.mu.RLock()
+	memberDone := member.done
+	member.mu.RUnlock()
+
+	for {
+		select {
+		case message := <-member.messageChan:
+			select {
+			case p.messageChan <- message:
+			case <-done:
+				return
+			}
+		case err := <-member.errChan:
+			select {
+			case p.errChan <- err:
+			case <-done:
+				return
+			}
+		case <-memberDone:
+			p.drain(member, done)
+
+			return
+		case <-done:
+			return
+		}
+	}
+}
+
+// drain sends messages and errors left in channels of closed member into pool channels,
+// so replacing member doesn't lose them.
+func (p *Pool) drain(member *Client, done chan struct{}) {
+	for {
+		select {
+		case message := <-member.messageChan:
+			select {
+			case p.messageChan <- message:
+			case <-done:
+				return
+			}
+		case err := <-member.errChan:
+			select {
+			case p.errChan <- err:
+			case <-done:
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// watch replaces member in slot once its connection is broken.
+func (p *Pool) watch(slot int, member *Client, done chan struct{}) {
+	for {
+		select {
+		case event := <-member.events:
+			if event.State == StateDisconnected {
+				p.replace(slot, member, done)
+
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// replace closes member in slot and dials new one until it's connected or pool is closed.
+func (p *Pool) replace(slot int, member *Client, done chan struct{}) {
+	for attempt := 1; ; attempt++ {
+		replacement := New(p.clientConf)
+
+		err := replacement.DialEndpoints(p.addrs, p.tlsConfig)
+		if err == nil {
+			p.mu.Lock()
+			if isDone(done) {
+				p.mu.Unlock()
+				_ = replacement.Close()
+
+				return
+			}
+
+			p.retire(member)
+			p.members[slot] = &poolMember{client: replacement, inFlight: 0}
+			p.mu.Unlock()
+
+			// Reader of broken member is gone, so its TLS stream is closed by force.
+			_ = member.Close()
+			_ = member.connection().Abort()
+
+			go p.forward(replacement, done)
+			go p.watch(slot, replacement, done)
+
+			return
+		}
+
+		if !p.clientConf.SuppressErrors {
+			select {
+			case p.errChan <- fmt.Errorf("[Pool] replacing connection: %w", err):
+			case <-done:
+				return
+			}
+		}
+
+		select {
+		case <-time.After(p.conf.Replace.backoff(attempt)):
+		case <-done:
+			return
+		}
+	}
+}
+
+// retire adds stats of member to stats of pool. It must be called under p.mu.
+func (p *Pool) retire(member *Client) {
+	snapshot := member.Snapshot()
+	p.retired.Sent += snapshot.Sent
+	p.retired.Received += snapshot.Received
+	p.retired.Errors += snapshot.Errors
+	p.retired.Connections += snapshot.Connections
+	p.retired.OutboxDropped += snapshot.OutboxDropped
+	p.retired.OutboxExpired += snapshot.OutboxExpired
+}
+
+// acquire chooses connected member by pool strategy and marks send in progress.
+func (p *Pool) acquire() (*poolMember, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.isClosed {
+		return nil, conn.ErrConnectionClosed
+	}
+
+	var chosen *poolMember
+
+	switch p.conf.Strategy {
+	case PoolRoundRobin:
+		for i := range p.members {
+			member := p.members[(p.next+i)%len(p.members)]
+			if member.client.Next() {
+				chosen = member
+				p.next = (p.next + i + 1) % len(p.members)
+
+				break
+			}
+		}
+	case PoolLeastBusy:
+		chosenSent := 0
+
+		for _, member := range p.members {
+			if !member.client.Next() {
+				continue
+			}
+
+			sent, _, _ := member.client.Stats()
+			if chosen == nil || member.inFlight < chosen.inFlight ||
+				(member.inFlight == chosen.inFlight && sent < chosenSent) {
+				chosen, chosenSent = member, sent
+			}
+		}
+	}
+
+	if chosen == nil {
+		return nil, conn.ErrConnectionClosed
+	}
+
+	chosen.inFlight++
+
+	return chosen, nil
+}
+
+// release marks send of member finished.
+func (p *Pool) release(member *poolMember) {
+	p.mu.Lock()
+	member.inFlight--
+	p.mu.Unlock()
+}
+
+// SendByte sends b through one of connections chosen by pool strategy.
+func (p *Pool) SendByte(b []byte) (int, error) {
+	return p.SendContext(context.Background(), b)
+}
+
+// SendString converts s into byte slice and calls to SendByte.
+func (p *Pool) SendString(s string) (int, error) {
+	return p.SendContext(context.Background(), []byte(s))
+}
+
+// SendContext sends b through one of connections chosen by pool strategy (see Client.SendContext).
+func (p *Pool) SendContext(ctx context.Context, b []byte) (int, error) {
+	member, err := p.acquire()
+	if err != nil {
+		return 0, fmt.Errorf("[Pool] send: %w", err)
+	}
+	defer p.release(member)
+
+	return member.client.SendContext(ctx, b)
+}
+
+// Request sends b through one of connections chosen by pool strategy and waits for the reply or ctx to be done.
+func (p *Pool) Request(ctx context.Context, b []byte) (*conn.Message, error) {
+	member, err := p.acquire()
+	if err != nil {
+		return nil, fmt.Errorf("[Pool] request: %w", err)
+	}
+	defer p.release(member)
+
+	return member.client.Request(ctx, b)
+}
+
+// GetMessage returns new message received by any connection of pool. Code will be locked until
+// new message appears or pool is closed. The only possible error is conn.ErrConnectionClosed.
+func (p *Pool) GetMessage() (*conn.Message, error) {
+	p.mu.RLock()
+	done := p.done
+	p.mu.RUnlock()
+
+	select {
+	case message := <-p.messageChan:
+		return message, nil
+	case <-done:
+		return nil, conn.ErrConnectionClosed
+	}
+}
+
+// ErrChan returns pool's error channel to read only. It gets errors of all connections
+// and errors of replacing broken ones.
+func (p *Pool) ErrChan() <-chan error {
+	return p.errChan
+}
+
+// Stats returns number of bytes sent/receive + number of errors of all connections, including replaced ones.
+func (p *Pool) Stats() (int, int, int) {
+	snapshot := p.Snapshot()
+
+	return snapshot.Sent, snapshot.Received, snapshot.Errors
+}
+
+// Snapshot returns stats of all connections summarized, including replaced ones.
+// Connections is the number of connections made by pool.
+func (p *Pool) Snapshot() StatsSnapshot {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	snapshot := p.retired
+
+	for _, member := range p.members {
+		s := member.client.Snapshot()
+		snapshot.Sent += s.Sent
+		snapshot.Received += s.Received
+		snapshot.Errors += s.Errors
+		snapshot.Connections += s.Connections
+		snapshot.OutboxDepth += s.OutboxDepth
+		snapshot.OutboxDropped += s.OutboxDropped
+		snapshot.OutboxExpired += s.OutboxExpired
+	}
+
+	return snapshot
+}
+
+// Active returns number of connections that are currently connected.
+func (p *Pool) Active() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	active := 0
+
+	for _, member := range p.members {
+		if member.client.Next() {
+			active++
+		}
+	}
+
+	return active
+}
+
+// Close closes all connections of pool and stops replacing them.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	if p.isClosed {
+		p.mu.Unlock()
+
+		return nil
+	}
+
+	p.isClosed = true
+	close(p.done)
+	members := p.members
+	p.mu.Unlock()
+
+	var err error
+
+	for _, member := range members {
+		if closeErr := member.client.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("[Pool] close: %w", err)
+	}
+
+	return nil
+}
+
+// Closed returns true if pool was closed or never dialed.
+func (p *Pool) Closed() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.isClosed
+}
+
+// isDone returns true if done is closed.
+func isDone(done chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,175 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lazybark/go-tls-server/conn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool(t *testing.T) {
+	srv, connections := newTestServer(t, "127.0.0.1:0")
+	addr := srv.Addrs()[0].String()
+
+	pool := NewPool(&PoolConfig{
+		Size:     3,
+		Strategy: PoolRoundRobin,
+		Replace:  &ReconnectPolicy{BaseBackoff: time.Millisecond * 20, MaxBackoff: time.Millisecond * 50},
+		Client:   &Config{SuppressErrors: true},
+	})
+	require.NoError(t, pool.DialWithConfig(addr, testTLSConfig))
+	assert.ErrorIs(t, pool.DialWithConfig(addr, testTLSConfig), ErrPoolDialed)
+	assert.Equal(t, 3, pool.Active())
+
+	members := make([]*conn.Connection, 0, 3)
+	for i := 0; i < 3; i++ {
+		members = append(members, <-connections)
+	}
+
+	// Round-robin sends one message through every connection.
+	for i := 0; i < 3; i++ {
+		_, err := pool.SendString("one")
+		require.NoError(t, err)
+	}
+
+	for _, member := range members {
+		message, err := member.GetMessage()
+		require.NoError(t, err)
+		assert.Equal(t, "one", string(message.Bytes()))
+	}
+
+	// Messages of all connections come into one channel.
+	for _, member := range members {
+		_, err := member.SendString("two")
+		require.NoError(t, err)
+	}
+
+	for i := 0; i < 3; i++ {
+		message, err := pool.GetMessage()
+		require.NoError(t, err)
+		assert.Equal(t, "two", string(message.Bytes()))
+	}
+
+	sent, received, _ := pool.Stats()
+	assert.Equal(t, 3*len("one\n"), sent)
+	assert.Equal(t, 3*len("two\n"), received)
+
+	// Broken connection is replaced, stats are kept.
+	require.NoError(t, members[0].Abort())
+
+	replacement := <-connections
+	assert.Eventually(t, func() bool { return pool.Active() == 3 }, time.Second*5, time.Millisecond*10)
+
+	snapshot := pool.Snapshot()
+	assert.Equal(t, 4, snapshot.Connections)
+	assert.Equal(t, 3*len("one\n"), snapshot.Sent)
+
+	for i := 0; i < 3; i++ {
+		_, err := pool.SendString("three")
+		require.NoError(t, err)
+	}
+
+	for _, member := range []*conn.Connection{replacement, members[1], members[2]} {
+		message, err := member.GetMessage()
+		require.NoError(t, err)
+		assert.Equal(t, "three", string(message.Bytes()))
+	}
+
+	require.NoError(t, pool.Close())
+	assert.True(t, pool.Closed())
+
+	_, err := pool.GetMessage()
+	assert.ErrorIs(t, err, conn.ErrConnectionClosed)
+
+	_, err = pool.SendString("four")
+	assert.ErrorIs(t, err, conn.ErrConnectionClosed)
+
+	require.NoError(t, srv.Stop())
+}
+
+func TestPoolLeastBusy(t *testing.T) {
+	srv, connections := newTestServer(t, "127.0.0.1:0")
+
+	pool := NewPool(&PoolConfig{Size: 2, Client: &Config{SuppressErrors: true}})
+	require.NoError(t, pool.DialWithConfig(srv.Addrs()[0].String(), testTLSConfig))
+
+	first, second := <-connections, <-connections
+
+	// Connection that sent fewer bytes is chosen.
+	_, err := pool.SendString("long message")
+	require.NoError(t, err)
+	_, err = pool.SendString("short")
+	require.NoError(t, err)
+	_, err = pool.SendString("short")
+	require.NoError(t, err)
+
+	received := map[string]int{}
+
+	for _, member := range []*conn.Connection{first, second} {
+		message, err := member.GetMessage()
+		require.NoError(t, err)
+		received[string(message.Bytes())]++
+	}
+
+	assert.Equal(t, map[string]int{"long message": 1, "short": 1}, received)
+
+	// Busy connection is skipped.
+	pool.mu.Lock()
+	pool.members[0].inFlight++
+	pool.mu.Unlock()
+
+	member, err := pool.acquire()
+	require.NoError(t, err)
+	assert.Equal(t, pool.members[1], member)
+
+	require.NoError(t, pool.Close())
+	require.NoError(t, srv.Stop())
+}
+
+func TestPoolReplaceClosesBrokenMember(t *testing.T) {
+	srv, connections := newTestServer(t, "127.0.0.1:0")
+
+	pool := NewPool(&PoolConfig{
+		Size:    1,
+		Replace: &ReconnectPolicy{BaseBackoff: time.Millisecond * 20, MaxBackoff: time.Millisecond * 50},
+		Client:  &Config{SuppressErrors: true, MaxMessageSize: 8},
+	})
+	require.NoError(t, pool.DialWithConfig(srv.Addrs()[0].String(), testTLSConfig))
+
+	broken := <-connections
+
+	// Too long message breaks member on client side only, server side is closed once member is replaced.
+	_, err := broken.SendString("too long message")
+	require.NoError(t, err)
+
+	<-connections
+	assert.Eventually(t, broken.Closed, time.Second*5, time.Millisecond*10)
+
+	require.NoError(t, pool.Close())
+	require.NoError(t, srv.Stop())
+}
+
+func TestPoolForwardDrainsClosedMember(t *testing.T) {
+	pool := NewPool(nil)
+	done := make(chan struct{})
+
+	// Member is closed already, but messages and errors it got before are still buffered.
+	member := New(nil)
+	for i := 0; i < 10; i++ {
+		member.messageChan <- conn.NewMessage(nil, 1, []byte{byte('0' + i)})
+	}
+	member.errChan <- conn.ErrConnectionClosed
+
+	pool.forward(member, done)
+
+	for i := 0; i < 10; i++ {
+		message := <-pool.messageChan
+		assert.Equal(t, []byte{byte('0' + i)}, message.Bytes())
+	}
+
+	assert.ErrorIs(t, <-pool.errChan, conn.ErrConnectionClosed)
+	assert.Empty(t, member.messageChan)
+	assert.Empty(t, member.errChan)
+}
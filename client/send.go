@@ -1,10 +1,16 @@
 package client
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+
+	"github.com/lazybark/go-tls-server/conn"
+)
 
 // SendByte sends bytes to remote by writing directrly into connection interface.
+// If client has outbox (see Config.Outbox) and is disconnected, bytes are queued and 0 bytes are reported as sent.
 func (c *Client) SendByte(b []byte) (int, error) {
-	count, err := c.conn.SendByte(b)
+	count, err := c.send(context.Background(), b)
 	if err != nil {
 		return count, c.FormatError(fmt.Errorf("[SendByte]: %w", err))
 	}
@@ -14,10 +20,63 @@ func (c *Client) SendByte(b []byte) (int, error) {
 
 // SendString converts s into byte slice and calls to SendByte.
 func (c *Client) SendString(s string) (int, error) {
-	count, err := c.conn.SendString(s)
+	count, err := c.send(context.Background(), []byte(s))
 	if err != nil {
 		return count, c.FormatError(fmt.Errorf("[SendString]: %w", err))
 	}
 
 	return count, nil
 }
+
+// SendContext works the same way as SendByte, but write deadline is taken from ctx and cancelling ctx
+// stops the write (see conn.Connection.SendContext). If client has outbox, ctx also limits the time sender
+// waits for room in it (see OverflowBlock) and its deadline expires queued message.
+// Stopped send returns *conn.CanceledError.
+func (c *Client) SendContext(ctx context.Context, b []byte) (int, error) {
+	count, err := c.send(ctx, b)
+	if err != nil {
+		return count, c.FormatError(fmt.Errorf("[SendContext]: %w", err))
+	}
+
+	return count, nil
+}
+
+// send queues b into outbox while client is disconnected or writes it into connection.
+func (c *Client) send(ctx context.Context, b []byte) (int, error) {
+	if c.outbox != nil {
+		c.mu.RLock()
+		// Client that was never connected queues messages until dial.
+		closed := c.isClosed && c.connCount > 0
+		done := c.done
+		c.mu.RUnlock()
+
+		if !closed {
+			queued, err := c.outbox.enqueue(ctx, done, b)
+			if queued {
+				return 0, err
+			}
+		}
+	}
+
+	return c.connection().SendContext(ctx, b)
+}
+
+// Request sends b to server and waits for the reply or ctx to be done.
+func (c *Client) Request(ctx context.Context, b []byte) (*conn.Message, error) {
+	reply, err := c.connection().Request(ctx, b)
+	if err != nil {
+		return nil, c.FormatError(fmt.Errorf("[Request]: %w", err))
+	}
+
+	return reply, nil
+}
+
+// Reply sends b as a reply to request m received from server.
+func (c *Client) Reply(m *conn.Message, b []byte) (int, error) {
+	count, err := c.connection().Reply(m, b)
+	if err != nil {
+		return count, c.FormatError(fmt.Errorf("[Reply]: %w", err))
+	}
+
+	return count, nil
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/lazybark/go-tls-server/conn"
+	"github.com/lazybark/go-tls-server/server"
+	"github.com/stretchr/testify/require"
+)
+
+// testTLSConfig is the client TLS config for test servers with self-signed certificates.
+var testTLSConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec,gochecknoglobals // test
+
+// newTestServer returns server that listens on addr and accepts every connection into returned channel.
+func newTestServer(t *testing.T, addr string) (*server.Server, chan *conn.Connection) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	srv, err := server.NewWithCertificates(context.Background(), "localhost", &server.Config{SuppressErrors: true},
+		tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key})
+	require.NoError(t, err)
+	require.NoError(t, srv.ListenAddr(addr))
+
+	connections := make(chan *conn.Connection, 10)
+
+	go func() {
+		for {
+			connection, err := srv.AcceptConnection()
+			if err != nil {
+				return
+			}
+
+			connections <- connection
+		}
+	}()
+
+	return srv, connections
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log"
 
+	"github.com/lazybark/go-tls-server/conn"
 	"github.com/lazybark/go-tls-server/server"
 )
 
@@ -36,29 +37,15 @@ func main() {
 		log.Fatal(err)
 	}
 
-	for tlsServer.Next() {
-		connection, err := tlsServer.AcceptConnection()
+	err = tlsServer.Serve(server.HandlerFunc(func(w server.ResponseWriter, m *conn.Message) {
+		log.Println("Got message:", string(m.Bytes()))
+
+		_, err := w.WriteString("Got ya!")
 		if err != nil {
-			if !errors.Is(err, server.ErrServerClosed) {
-				log.Fatal(err)
-			}
+			log.Println(err)
 		}
-
-		go func() {
-			for connection.Next() {
-				message, err := connection.GetMessage()
-				if err != nil {
-					return
-				}
-
-				log.Println("Got message:", string(message.Bytes()))
-
-				err = tlsServer.SendString(connection, "Got ya!")
-				if err != nil {
-					log.Fatal(err)
-				}
-			}
-		}()
-
+	}))
+	if err != nil && !errors.Is(err, server.ErrServerClosed) {
+		log.Fatal(err)
 	}
 }
@@ -0,0 +1,16 @@
+package conn
+
+import "strings"
+
+// UnixScheme is the prefix of Unix domain socket addresses, e.g. "unix:///run/app.sock".
+const UnixScheme = "unix://"
+
+// ParseAddress returns network and address to use in net.Dial / net.Listen for addr.
+// Addresses like "unix:///path.sock" are Unix domain sockets, any other address is TCP host:port.
+func ParseAddress(addr string) (string, string) {
+	if strings.HasPrefix(addr, UnixScheme) {
+		return "unix", strings.TrimPrefix(addr, UnixScheme)
+	}
+
+	return "tcp", addr
+}
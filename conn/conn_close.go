@@ -28,6 +28,16 @@ func (c *Connection) Close() error {
 	return c.close()
 }
 
+// Abort closes connection and the TLS stream at once, so active reader is broken right away.
+// Use it when connection has to be dropped without waiting for reader to notice that it's closed.
+func (c *Connection) Abort() error {
+	if !c.Closed() {
+		_ = c.close()
+	}
+
+	return c.closeTLS()
+}
+
 // close marks connection as closed, but TLS will be closed by reader.
 func (c *Connection) close() error {
 	c.mu.Lock()
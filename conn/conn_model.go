@@ -52,13 +52,34 @@ type Connection struct {
 	// errors holds total number of errors occurred in connection.
 	errors int
 
-	// MessageTerminator sets byte value that marks message end in the stream.
-	// Works for both incoming and outgoing messages.
-	messageTerminator byte
+	// framer separates messages in the stream. Works for both incoming and outgoing messages.
+	framer Framer
 
 	// messageChan channel to notify external routine about new messages.
 	messageChan chan *Message
 
+	// messageChanOnce makes sure message channel is closed only once.
+	messageChanOnce sync.Once
+
+	// pending holds channels of requests that wait for reply, by correlation ID.
+	pending map[uint64]chan *Message
+
+	// lastRequestID is the correlation ID of last request sent.
+	lastRequestID uint64
+
+	// inFlight is the number of messages that were read, but not processed yet.
+	inFlight int
+
+	// served is true if messages are taken by a routine that releases them from flight after processing.
+	served bool
+
+	// partial is the number of bytes of a message that was not read completely yet.
+	partial int
+
+	// writeSlot is the 1-slot semaphore that serializes writes, so write deadline of one SendContext
+	// doesn't affect the others. Unlike mutex, it can be waited for with ctx.
+	writeSlot chan struct{}
+
 	mu *sync.RWMutex
 }
 
@@ -69,7 +90,28 @@ func (c *Connection) Address() net.Addr { return c.addr }
 func (c *Connection) ID() string { return c.id }
 
 // SetMessageTerminator sets byte that will be used as message terminator.
-func (c *Connection) SetMessageTerminator(t byte) { c.messageTerminator = t }
+// It switches connection to TerminatorFramer.
+func (c *Connection) SetMessageTerminator(t byte) { c.SetFramer(NewTerminatorFramer(t)) }
+
+// SetMessageDelimiter sets bytes that will be used as message delimiter.
+// It switches connection to TerminatorFramer.
+func (c *Connection) SetMessageDelimiter(d []byte) { c.SetFramer(NewDelimiterFramer(d)) }
+
+// SetFramer sets framer that will be used to send and read messages.
+func (c *Connection) SetFramer(f Framer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.framer = f
+}
+
+// Framer returns current framer of the connection.
+func (c *Connection) Framer() Framer {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.framer
+}
 
 // Next returns true if connection is open and able to receive new messages.
 func (c *Connection) Next() bool {
@@ -90,5 +132,63 @@ func (c *Connection) GetMessage() (*Message, error) {
 // MessageChanWrite returns connection's message channel to write only.
 func (c *Connection) MessageChanWrite() chan<- *Message { return c.messageChan }
 
+// CloseMessageChan closes message channel, so GetMessage returns ErrConnectionClosed after all messages are read.
+// It should be called by the routine that writes into MessageChanWrite once it stops.
+func (c *Connection) CloseMessageChan() { c.messageChanOnce.Do(func() { close(c.messageChan) }) }
+
 // CancelCtx cancels the connection context.
 func (c *Connection) CancelCtx() { c.cancel() }
+
+// Done returns channel that is closed when connection is closed.
+func (c *Connection) Done() <-chan struct{} { return c.ctx.Done() }
+
+// AddInFlight changes number of messages that were read, but not processed yet.
+// Routines that deliver and process messages use it to let others know that connection is busy.
+func (c *Connection) AddInFlight(delta int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inFlight += delta
+}
+
+// SetServed marks connection as read by a routine that releases every message taken with AddInFlight(-1)
+// after processing it (e.g. handler of server.Serve). Messages of connection that is not served are released
+// as soon as they are taken.
+func (c *Connection) SetServed() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.served = true
+}
+
+// Served returns true if connection was marked by SetServed.
+func (c *Connection) Served() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.served
+}
+
+// InFlight returns number of messages that were read, but not processed yet.
+func (c *Connection) InFlight() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.inFlight
+}
+
+// Idle returns true if connection has no messages in flight and no partially read message.
+func (c *Connection) Idle() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.inFlight == 0 && c.partial == 0
+}
+
+// setPartial sets number of bytes of a message that was not read completely yet.
+func (c *Connection) setPartial(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.partial = n
+}
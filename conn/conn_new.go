@@ -16,6 +16,7 @@ func NewConnection(address net.Addr, conn net.Conn, terminator byte) (*Connectio
 	connection.lastAct = connection.connectedAt
 	connection.messageChan = make(chan *Message)
 	connection.mu = &sync.RWMutex{}
+	connection.writeSlot = make(chan struct{}, 1)
 
 	connID, err := uuid.NewV4()
 	if err != nil {
@@ -1,37 +1,94 @@
 package conn
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
 )
 
-// readWithContext reads bytes from connection until Terminator / error occurs or context is done.
+// ReadWithContext reads bytes from connection until Terminator / error occurs or context is done.
 // It can be used to read with timeout or any other way to break reader.
 // Usual readers are vulnerable to routine-leaks, so this way is more confident.
 //
+// If framer of the connection is a TerminatorFramer with the same terminator, it's used to read,
+// so escaped messages (see NewEscapingTerminatorFramer) are unescaped. Otherwise plain terminator
+// framing is used. Use ReadMessage to read with any framer set by SetFramer.
+//
 // IMPORTANT: if EOF or context deadline appear, readWithContext will mark connection as 'closed'.
 // Other errors should be treated manually by external code.
 // In all cases method will return last bytes read.
-func (c *Connection) ReadWithContext(buffer, maxSize int, terminator byte) ([]byte, int, error) { //nolint:all // It's OK
+func (c *Connection) ReadWithContext(buffer, maxSize int, terminator byte) ([]byte, int, error) {
+	bytes, read, err := c.readFrame(buffer, maxSize, c.delimiterFramer([]byte{terminator}))
+	if err != nil {
+		return bytes, read, fmt.Errorf("[ReadWithContext] %w", err)
+	}
+
+	return bytes, read, nil
+}
+
+// ReadWithDelimiter works the same way as ReadWithContext, but message end is marked
+// with multi-byte delimiter (e.g. "\r\n").
+func (c *Connection) ReadWithDelimiter(buffer, maxSize int, delimiter []byte) ([]byte, int, error) {
+	bytes, read, err := c.readFrame(buffer, maxSize, c.delimiterFramer(delimiter))
+	if err != nil {
+		return bytes, read, fmt.Errorf("[ReadWithDelimiter] %w", err)
+	}
+
+	return bytes, read, nil
+}
+
+// delimiterFramer returns framer of the connection if it's a TerminatorFramer with delimiter,
+// so escaping settings are kept. Otherwise it returns plain framer with delimiter.
+func (c *Connection) delimiterFramer(delimiter []byte) Framer {
+	if framer, ok := c.Framer().(*TerminatorFramer); ok && bytes.Equal(framer.delimiter, delimiter) {
+		return framer
+	}
+
+	return NewDelimiterFramer(delimiter)
+}
+
+// ReadMessage works the same way as ReadWithContext, but extracts messages from stream
+// using framer of the connection.
+//
+// Returned bytes are nil if reading was stopped without a message. Message may be taken from bytes
+// left after previous one, so number of bytes read can be 0 for a valid message.
+func (c *Connection) ReadMessage(buffer, maxSize int) ([]byte, int, error) {
+	bytes, read, err := c.readFrame(buffer, maxSize, c.Framer())
+	if err != nil {
+		return bytes, read, fmt.Errorf("[ReadMessage] %w", err)
+	}
+
+	return bytes, read, nil
+}
+
+// readFrame reads from connection until framer finds complete message in the stream.
+func (c *Connection) readFrame(buffer, maxSize int, framer Framer) ([]byte, int, error) { //nolint:cyclop // It's OK
 	if c.Closed() {
-		return nil, 0, fmt.Errorf("[ReadWithContext] %w", ErrReaderAlreadyClosed)
+		return nil, 0, ErrReaderAlreadyClosed
 	}
 
 	// Using c.conn.SetReadDeadline(time) in that case will make connection process less flexible.
 	// Instead, checking ctx gives us a way to handle timeouts by the server itself.
 	// We can, for example, close connection after some inactivity period by checking c.lastAct.
 
-	var readBytes []byte
-	// Appending bytes that left from prev message in case terminator was not the last byte.
-	if len(c.bytesLeft) > 0 {
-		readBytes = append(readBytes, c.bytesLeft...)
-		c.bytesLeft = []byte{}
-	}
-
 	// Length of current read.
 	read := 0
 	defer func(read *int) { c.AddRecBytes(*read) }(&read)
+	// Whatever is left unprocessed after reading is a part of the next message.
+	defer func() { c.setPartial(len(c.bytesLeft)) }()
+
+	// Starting with bytes that left from prev message in case frame end was not the last byte read.
+	// They may already hold one or more complete messages.
+	readBytes := c.bytesLeft
+	c.bytesLeft = nil
+
+	if len(readBytes) > 0 {
+		message, ok, err := c.splitFrame(framer, readBytes, maxSize)
+		if err != nil || ok {
+			return message, read, err
+		}
+	}
 
 	// Read buffer with server-defined size.
 	bytes := make([]byte, buffer)
@@ -47,10 +104,11 @@ func (c *Connection) ReadWithContext(buffer, maxSize int, terminator byte) ([]by
 			countRead, err := c.tlsConn.Read(bytes)
 			if err != nil {
 				if errors.Is(err, io.EOF) {
-					return nil, read, fmt.Errorf("[ReadWithContext] %w", ErrStreamClosed)
+					return nil, read, ErrStreamClosed
 				}
 
-				if c.ctx.Done() != nil {
+				// Reading was broken by context.
+				if c.ctx.Err() != nil {
 					_ = c.closeTLS() // We close TLS only by reader
 
 					return nil, read, nil
@@ -59,34 +117,48 @@ func (c *Connection) ReadWithContext(buffer, maxSize int, terminator byte) ([]by
 				c.AddErrors(1)
 				// The connecton is not closed yet in this case!
 				// Client code should decide if they want to close or try to read next bytes.
-				return nil, read, fmt.Errorf("[ReadWithContext] reading error: %w", err)
+				return nil, read, fmt.Errorf("reading error: %w", err)
 			}
 
 			read += countRead
 
 			c.SetLastAct()
 
-			if maxSize > 0 && read > maxSize {
-				c.AddErrors(1)
-
-				return nil, read, fmt.Errorf("[ReadWithContext] %w (read %v of max %v)", ErrMessageSizeLimit, read, maxSize)
-			}
-			// We check every byte searching for terminator.
-			for num, by := range bytes[:countRead] {
-				if by == terminator {
-					readBytes = append(readBytes, bytes[:num]...)
-					// We collect extra bytes in case there is something left from prev message and pass on to next one
-					// This can happen in cases when client sends data in a stream-way, not portionally
-					// These bytes will be picked up with next trigger of reader as if they were sent with next message itself.
-					if len(bytes[num:countRead]) > 0 {
-						c.bytesLeft = bytes[num:countRead]
-					}
-
-					return readBytes, read, nil
-				}
+			if countRead == 0 {
+				continue
 			}
 
 			readBytes = append(readBytes, bytes[:countRead]...)
+
+			message, ok, err := c.splitFrame(framer, readBytes, maxSize)
+			if err != nil || ok {
+				return message, read, err
+			}
+
+			c.setPartial(len(readBytes))
 		}
 	}
 }
+
+// splitFrame calls to framer and saves bytes that go after the message.
+// We collect extra bytes in case there is something left from prev message and pass on to next one.
+// This can happen in cases when client sends data in a stream-way, not portionally.
+// These bytes will be picked up with next trigger of reader as if they were sent with next message itself.
+func (c *Connection) splitFrame(framer Framer, data []byte, maxSize int) ([]byte, bool, error) {
+	message, advance, err := framer.Split(data, maxSize)
+	if err != nil {
+		c.AddErrors(1)
+
+		return nil, false, err
+	}
+
+	if advance == 0 {
+		return nil, false, nil
+	}
+
+	if advance < len(data) {
+		c.bytesLeft = append([]byte(nil), data[advance:]...)
+	}
+
+	return message, true, nil
+}
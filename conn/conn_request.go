@@ -0,0 +1,169 @@
+package conn
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// messageKind defines whether message is plain, request or reply.
+type messageKind byte
+
+const (
+	kindPlain   messageKind = 0
+	kindRequest messageKind = 'Q'
+	kindReply   messageKind = 'S'
+)
+
+const (
+	// requestMagic starts payload of every request and reply. Plain messages starting
+	// with these bytes will be treated as requests or replies.
+	requestMagic = "\xffR:"
+
+	// requestIDLen is the length of correlation ID written as lowercase hex digits.
+	// Header is text after the magic, so it never contains newline, zero or other control bytes
+	// usually used as message delimiter.
+	requestIDLen = 16
+
+	// requestHeaderLen is the length of magic, message kind and hex correlation ID.
+	requestHeaderLen = len(requestMagic) + 1 + requestIDLen
+)
+
+// Request sends payload to remote and waits for the reply. Other messages received meanwhile
+// are delivered to GetMessage as usual.
+//
+// Remote should answer with Message.Reply. Request returns error if connection is closed or ctx is done
+// (*CanceledError) before the reply arrives. Replies that come after that are dropped.
+//
+// IMPORTANT: reply is delivered by the routine that reads connection, so some routine should
+// keep receiving messages from GetMessage, otherwise reader will be blocked by unsolicited messages.
+func (c *Connection) Request(ctx context.Context, payload []byte) (*Message, error) {
+	reply, _, err := c.RequestSent(ctx, payload)
+
+	return reply, err
+}
+
+// RequestSent works the same way as Request, but also returns number of bytes written by request,
+// so caller can count them without mixing in other sends of the connection.
+func (c *Connection) RequestSent(ctx context.Context, payload []byte) (*Message, int, error) {
+	replyChan := make(chan *Message, 1)
+
+	c.mu.Lock()
+	c.lastRequestID++
+	requestID := c.lastRequestID
+
+	if c.pending == nil {
+		c.pending = make(map[uint64]chan *Message)
+	}
+
+	c.pending[requestID] = replyChan
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, requestID)
+		c.mu.Unlock()
+	}()
+
+	message, err := c.withRequestHeader(kindRequest, requestID, payload)
+	if err != nil {
+		return nil, 0, fmt.Errorf("[Request] %w", err)
+	}
+
+	sent, err := c.SendContext(ctx, message)
+	if err != nil {
+		return nil, sent, fmt.Errorf("[Request] %w", err)
+	}
+
+	select {
+	case reply := <-replyChan:
+		return reply, sent, nil
+	case <-ctx.Done():
+		return nil, sent, fmt.Errorf("[Request] %w", &CanceledError{Op: "Request", Err: ctx.Err()})
+	case <-c.ctx.Done():
+		return nil, sent, fmt.Errorf("[Request] %w", ErrConnectionClosed)
+	}
+}
+
+// Reply sends payload as a reply to request m.
+func (c *Connection) Reply(m *Message, payload []byte) (int, error) {
+	if !m.IsRequest() {
+		return 0, fmt.Errorf("[Reply] %w", ErrNotRequest)
+	}
+
+	message, err := c.withRequestHeader(kindReply, m.RequestID(), payload)
+	if err != nil {
+		return 0, fmt.Errorf("[Reply] %w", err)
+	}
+
+	return c.SendByte(message)
+}
+
+// Resolve passes reply m to the Request that waits for it. It returns true if m was a reply
+// and should not be delivered to GetMessage. Readers should call it for every message received.
+func (c *Connection) Resolve(m *Message) bool {
+	if !m.IsReply() {
+		return false
+	}
+
+	c.mu.Lock()
+	replyChan, ok := c.pending[m.RequestID()]
+	delete(c.pending, m.RequestID())
+	c.mu.Unlock()
+
+	if ok {
+		replyChan <- m
+	}
+
+	return true
+}
+
+// withRequestHeader puts request header before payload. It returns ErrDelimiterInHeader if framer
+// of the connection doesn't escape payload and header has its delimiter, so frame would be cut.
+func (c *Connection) withRequestHeader(kind messageKind, requestID uint64, payload []byte) ([]byte, error) {
+	message := withRequestHeader(kind, requestID, payload)
+
+	framer, ok := c.Framer().(*TerminatorFramer)
+	if ok && !framer.escape && bytes.Contains(message[:requestHeaderLen], framer.delimiter) {
+		return nil, fmt.Errorf("%w (%q)", ErrDelimiterInHeader, framer.delimiter)
+	}
+
+	return message, nil
+}
+
+// withRequestHeader puts request header before payload.
+func withRequestHeader(kind messageKind, requestID uint64, payload []byte) []byte {
+	message := make([]byte, 0, requestHeaderLen+len(payload))
+	message = append(message, requestMagic...)
+	message = append(message, byte(kind))
+	message = append(message, fmt.Sprintf("%016x", requestID)...)
+
+	return append(message, payload...)
+}
+
+// parseRequestHeader returns message kind, correlation ID and payload without header.
+func parseRequestHeader(bytes []byte) (messageKind, uint64, []byte) {
+	if len(bytes) < requestHeaderLen || string(bytes[:len(requestMagic)]) != requestMagic {
+		return kindPlain, 0, bytes
+	}
+
+	kind := messageKind(bytes[len(requestMagic)])
+	if kind != kindRequest && kind != kindReply {
+		return kindPlain, 0, bytes
+	}
+
+	id := bytes[len(requestMagic)+1 : requestHeaderLen]
+	for _, b := range id {
+		if (b < '0' || b > '9') && (b < 'a' || b > 'f') {
+			return kindPlain, 0, bytes
+		}
+	}
+
+	requestID, err := strconv.ParseUint(string(id), 16, 64)
+	if err != nil {
+		return kindPlain, 0, bytes
+	}
+
+	return kind, requestID, bytes[requestHeaderLen:]
+}
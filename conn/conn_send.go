@@ -1,11 +1,32 @@
 package conn
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
 
 // SendByte sends bytes to remote by writing directrly into connection interface.
+// Bytes are wrapped into frame by the connection framer. Sends of the connection are made one by one.
 func (c *Connection) SendByte(bytesToSend []byte) (int, error) {
-	bytesToSend = append(bytesToSend, c.messageTerminator)
-	sentCount, err := c.tlsConn.Write(bytesToSend)
+	c.writeSlot <- struct{}{}
+	defer c.releaseWrite()
+
+	return c.send(bytesToSend)
+}
+
+// send frames bytes and writes them into connection. It must be called with write slot taken.
+func (c *Connection) send(bytesToSend []byte) (int, error) {
+	frame, err := c.Framer().Frame(bytesToSend)
+	if err != nil {
+		c.AddErrors(1)
+
+		return 0, fmt.Errorf("[SendByte] error framing message: %w", err)
+	}
+
+	sentCount, err := c.tlsConn.Write(frame)
 
 	c.AddSentBytes(sentCount)
 	c.SetLastAct()
@@ -21,3 +42,71 @@ func (c *Connection) SendByte(bytesToSend []byte) (int, error) {
 
 // SendString converts s into byte slice and calls to SendByte.
 func (c *Connection) SendString(s string) (int, error) { return c.SendByte([]byte(s)) }
+
+// SendContext works the same way as SendByte, but write deadline is taken from ctx and cancelling ctx
+// stops the write. It returns *CanceledError if ctx is done before or during the write.
+// It's safe for concurrent use: sends wait for each other, so deadline of one send never affects another.
+// Waiting for other sends is limited by ctx as well.
+//
+// IMPORTANT: message that was written partially can't be finished, so connection should be closed
+// after write was stopped.
+func (c *Connection) SendContext(ctx context.Context, payload []byte) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, &CanceledError{Op: "SendContext", Err: err}
+	}
+
+	// Waiting for other sends is stopped by ctx too, so stuck send doesn't block this one forever.
+	select {
+	case c.writeSlot <- struct{}{}:
+	case <-ctx.Done():
+		return 0, &CanceledError{Op: "SendContext", Err: ctx.Err()}
+	}
+	defer c.releaseWrite()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = c.tlsConn.SetWriteDeadline(deadline)
+	}
+
+	// Cancelling ctx moves write deadline to now, so blocked write returns.
+	var stop, stopped chan struct{}
+
+	if ctx.Done() != nil {
+		stop, stopped = make(chan struct{}), make(chan struct{})
+
+		go func() {
+			defer close(stopped)
+
+			select {
+			case <-ctx.Done():
+				_ = c.tlsConn.SetWriteDeadline(time.Now())
+			case <-stop:
+			}
+		}()
+	}
+
+	sentCount, err := c.send(payload)
+
+	// Watcher must be gone before deadline is reset, otherwise it could set the deadline again
+	// and break the next send.
+	if stop != nil {
+		close(stop)
+		<-stopped
+	}
+
+	_ = c.tlsConn.SetWriteDeadline(time.Time{})
+
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return sentCount, &CanceledError{Op: "SendContext", Err: ctxErr}
+		}
+
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return sentCount, &CanceledError{Op: "SendContext", Err: context.DeadlineExceeded}
+		}
+	}
+
+	return sentCount, err
+}
+
+// releaseWrite frees write slot taken by send.
+func (c *Connection) releaseWrite() { <-c.writeSlot }
@@ -10,6 +10,49 @@ func (c *Connection) Stats() (int, int, int) {
 	return c.bs, c.br, c.errors
 }
 
+// StatsSnapshot holds stats of connection at some moment.
+type StatsSnapshot struct {
+	ID          string
+	Address     string
+	ConnectedAt time.Time
+	LastAct     time.Time
+	Closed      bool
+
+	// Sent, Received and Errors are the same as returned by Stats.
+	Sent     int
+	Received int
+	Errors   int
+
+	// InFlight is the number of messages that were read, but not processed yet.
+	InFlight int
+
+	// TLS is the negotiated TLS state, nil if connection is not a TLS one.
+	TLS *TLSState
+}
+
+// Snapshot returns current stats of connection, including TLS state.
+func (c *Connection) Snapshot() StatsSnapshot {
+	sent, received, errors := c.Stats()
+
+	address := ""
+	if c.addr != nil {
+		address = c.addr.String()
+	}
+
+	return StatsSnapshot{
+		ID:          c.id,
+		Address:     address,
+		ConnectedAt: c.ConnectedAt(),
+		LastAct:     c.LastAct(),
+		Closed:      c.Closed(),
+		Sent:        sent,
+		Received:    received,
+		Errors:      errors,
+		InFlight:    c.InFlight(),
+		TLS:         c.TLSState(),
+	}
+}
+
 // DropOldStats sets bytes received, sent and error count to zero.
 func (c *Connection) DropOldStats() {
 	c.mu.Lock()
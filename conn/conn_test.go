@@ -1,7 +1,12 @@
 package conn_test
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
 	"errors"
+	"io"
+	"net"
 	"testing"
 	"time"
 
@@ -261,3 +266,500 @@ func TestConnectionReadingStats(t *testing.T) {
 	assert.Equal(t, 0, sent)
 
 }
+
+func TestConnectionLengthPrefixFraming(t *testing.T) {
+	send := []byte("Hello there,\nGeneral Kenobi!\n")
+
+	for _, prefix := range []conn.LengthPrefix{conn.PrefixFixed32, conn.PrefixUvarint} {
+		tlsConn := &mock.MockTLSConnection{
+			MWR: mock.MockWriteReader{DontReturEOFEver: true},
+		}
+
+		cn, err := conn.NewConnection(tlsConn.RemoteAddr(), tlsConn, '\n')
+		require.NoError(t, err)
+
+		cn.SetFramer(conn.NewLengthPrefixFramer(prefix))
+
+		// Two messages in one stream, so second one is picked from bytes left after the first.
+		_, err = cn.SendByte(send)
+		require.NoError(t, err)
+		_, err = cn.SendByte(send[:5])
+		require.NoError(t, err)
+
+		read, _, err := cn.ReadMessage(5, 100)
+		require.NoError(t, err)
+		assert.Equal(t, string(send), string(read))
+
+		read, _, err = cn.ReadMessage(5, 100)
+		require.NoError(t, err)
+		assert.Equal(t, string(send[:5]), string(read))
+
+		sent, rec, errs := cn.Stats()
+		assert.Equal(t, sent, rec)
+		assert.Equal(t, 0, errs)
+	}
+}
+
+func TestConnectionLengthPrefixSizeLimit(t *testing.T) {
+	framer := conn.NewLengthPrefixFramer(conn.PrefixFixed32)
+	frame, err := framer.Frame([]byte("Hello there, General Kenobi!"))
+	require.NoError(t, err)
+
+	tlsConn := &mock.MockTLSConnection{
+		MWR: mock.MockWriteReader{
+			Bytes:            frame,
+			DontReturEOFEver: true,
+		},
+	}
+
+	cn, err := conn.NewConnection(tlsConn.RemoteAddr(), tlsConn, '\n')
+	require.NoError(t, err)
+
+	cn.SetFramer(framer)
+
+	// Limit is checked right after the header, payload is not read.
+	read, count, err := cn.ReadMessage(4, 5)
+	assert.True(t, errors.Is(err, conn.ErrMessageSizeLimit))
+	assert.Empty(t, read)
+	assert.Equal(t, 4, count)
+	assert.Equal(t, 1, cn.Errors())
+}
+
+func TestConnectionReadingBytesLeft(t *testing.T) {
+	tlsConn := &mock.MockTLSConnection{
+		MWR: mock.MockWriteReader{
+			Bytes:            []byte("Hello there!\nGeneral Kenobi!\n"),
+			DontReturEOFEver: true,
+		},
+	}
+
+	cn, err := conn.NewConnection(tlsConn.RemoteAddr(), tlsConn, '\n')
+	require.NoError(t, err)
+
+	// Whole stream is read at once, second message comes from bytes left.
+	read, _, err := cn.ReadMessage(100, 100)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello there!", string(read))
+
+	read, count, err := cn.ReadMessage(100, 100)
+	require.NoError(t, err)
+	assert.Equal(t, "General Kenobi!", string(read))
+	assert.Equal(t, 0, count)
+}
+
+func TestConnectionEscapedTerminatorFraming(t *testing.T) {
+	send := []byte{'H', 'i', '\n', conn.EscapeByte, '!', '\n'}
+
+	tlsConn := &mock.MockTLSConnection{
+		MWR: mock.MockWriteReader{DontReturEOFEver: true},
+	}
+
+	cn, err := conn.NewConnection(tlsConn.RemoteAddr(), tlsConn, '\n')
+	require.NoError(t, err)
+
+	cn.SetFramer(conn.NewEscapingTerminatorFramer('\n'))
+
+	_, err = cn.SendByte(send)
+	require.NoError(t, err)
+
+	// Only one terminator left in stream: the one that ends the message.
+	assert.Equal(t, 1, bytes.Count(tlsConn.MWR.Bytes, []byte{'\n'}))
+	assert.Equal(t, byte('\n'), tlsConn.MWR.Bytes[len(tlsConn.MWR.Bytes)-1])
+
+	read, count, err := cn.ReadMessage(3, 100)
+	require.NoError(t, err)
+	assert.Equal(t, send, read)
+	assert.Equal(t, len(tlsConn.MWR.Bytes), count)
+
+	// ReadWithContext unescapes the same way if terminator matches framer.
+	_, err = cn.SendByte(send)
+	require.NoError(t, err)
+
+	read, _, err = cn.ReadWithContext(3, 100, '\n')
+	require.NoError(t, err)
+	assert.Equal(t, send, read)
+}
+
+func TestConnectionEscapedTerminatorMalformed(t *testing.T) {
+	tlsConn := &mock.MockTLSConnection{
+		MWR: mock.MockWriteReader{
+			Bytes:            []byte{'H', 'i', conn.EscapeByte, '\n'},
+			DontReturEOFEver: true,
+		},
+	}
+
+	cn, err := conn.NewConnection(tlsConn.RemoteAddr(), tlsConn, '\n')
+	require.NoError(t, err)
+
+	cn.SetFramer(conn.NewEscapingTerminatorFramer('\n'))
+
+	read, _, err := cn.ReadMessage(10, 100)
+	assert.True(t, errors.Is(err, conn.ErrMalformedFrame))
+	assert.Empty(t, read)
+	assert.Equal(t, 1, cn.Errors())
+}
+
+func TestConnectionMultiByteDelimiter(t *testing.T) {
+	delimiter := []byte("\x00\x00END")
+
+	for _, buffer := range []int{1, 2, 3, 5, 128} {
+		tlsConn := &mock.MockTLSConnection{
+			MWR: mock.MockWriteReader{
+				// Second message has a part of delimiter inside.
+				Bytes:            []byte("Hello there!\x00\x00ENDGeneral\x00\x00EN Kenobi!\x00\x00END"),
+				DontReturEOFEver: true,
+			},
+		}
+
+		cn, err := conn.NewConnection(tlsConn.RemoteAddr(), tlsConn, '\n')
+		require.NoError(t, err)
+
+		cn.SetMessageDelimiter(delimiter)
+
+		read, count1, err := cn.ReadMessage(buffer, 100)
+		require.NoError(t, err)
+		assert.Equal(t, "Hello there!", string(read))
+
+		read, count2, err := cn.ReadMessage(buffer, 100)
+		require.NoError(t, err)
+		assert.Equal(t, "General\x00\x00EN Kenobi!", string(read))
+
+		assert.Equal(t, len(tlsConn.MWR.Bytes), count1+count2)
+	}
+}
+
+func TestConnectionMultiByteDelimiterEscaping(t *testing.T) {
+	delimiter := []byte("\r\n")
+	send := []byte("Hello\r\nthere\r")
+
+	tlsConn := &mock.MockTLSConnection{
+		MWR: mock.MockWriteReader{DontReturEOFEver: true},
+	}
+
+	cn, err := conn.NewConnection(tlsConn.RemoteAddr(), tlsConn, '\n')
+	require.NoError(t, err)
+
+	cn.SetFramer(conn.NewEscapingDelimiterFramer(delimiter))
+
+	_, err = cn.SendByte(send)
+	require.NoError(t, err)
+	assert.Equal(t, 1, bytes.Count(tlsConn.MWR.Bytes, delimiter))
+
+	read, _, err := cn.ReadMessage(2, 100)
+	require.NoError(t, err)
+	assert.Equal(t, send, read)
+
+	// ReadWithDelimiter unescapes the same way if delimiter matches framer.
+	_, err = cn.SendByte(send)
+	require.NoError(t, err)
+
+	read, _, err = cn.ReadWithDelimiter(2, 100, delimiter)
+	require.NoError(t, err)
+	assert.Equal(t, send, read)
+}
+
+func TestConnectionRequest(t *testing.T) {
+	local, remote := net.Pipe()
+
+	cnLocal, err := conn.NewConnection(local.RemoteAddr(), local, '\n')
+	require.NoError(t, err)
+	cnRemote, err := conn.NewConnection(remote.RemoteAddr(), remote, '\n')
+	require.NoError(t, err)
+
+	// Remote answers requests and passes plain messages further.
+	plain := make(chan string, 1)
+	go func() {
+		for {
+			read, _, err := cnRemote.ReadMessage(128, 0)
+			if err != nil || read == nil {
+				return
+			}
+
+			m := conn.NewMessage(cnRemote, len(read), read)
+			if !m.IsRequest() {
+				plain <- string(m.Bytes())
+
+				continue
+			}
+
+			_, _ = m.Reply(append([]byte("re: "), m.Bytes()...))
+		}
+	}()
+
+	go func() {
+		for {
+			read, _, err := cnLocal.ReadMessage(128, 0)
+			if err != nil || read == nil {
+				return
+			}
+
+			cnLocal.Resolve(conn.NewMessage(cnLocal, len(read), read))
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+
+	reply, err := cnLocal.Request(ctx, []byte("ping"))
+	require.NoError(t, err)
+	assert.True(t, reply.IsReply())
+	assert.Equal(t, "re: ping", string(reply.Bytes()))
+	assert.Equal(t, len("re: ping"), reply.Length())
+
+	// Request IDs never cut the frame, whatever bytes they have.
+	for i := 0; i < 300; i++ {
+		reply, err = cnLocal.Request(ctx, []byte("ping"))
+		require.NoError(t, err)
+		assert.Equal(t, "re: ping", string(reply.Bytes()))
+	}
+
+	// Header (20 bytes), payload and terminator are counted as sent by request.
+	reply, sent, err := cnLocal.RequestSent(ctx, []byte("ping"))
+	require.NoError(t, err)
+	assert.Equal(t, "re: ping", string(reply.Bytes()))
+	assert.Equal(t, 20+len("ping\n"), sent)
+
+	_, err = cnLocal.SendString("plain")
+	require.NoError(t, err)
+	assert.Equal(t, "plain", <-plain)
+
+	// Replying to plain message is an error.
+	_, err = cnRemote.Reply(conn.NewMessage(cnRemote, 5, []byte("plain")), nil)
+	assert.True(t, errors.Is(err, conn.ErrNotRequest))
+
+	_ = local.Close()
+	_ = remote.Close()
+}
+
+func TestConnectionRequestDelimiterInHeader(t *testing.T) {
+	tlsConn := &mock.MockTLSConnection{}
+
+	cn, err := conn.NewConnection(tlsConn.RemoteAddr(), tlsConn, 'R')
+	require.NoError(t, err)
+
+	_, err = cn.Request(context.Background(), []byte("ping"))
+	assert.True(t, errors.Is(err, conn.ErrDelimiterInHeader))
+	assert.Empty(t, tlsConn.MWR.Bytes)
+
+	// Escaping framer keeps header whole.
+	cn.SetFramer(conn.NewEscapingTerminatorFramer('R'))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+
+	_, err = cn.Request(ctx, []byte("ping"))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestConnectionRequestTimeout(t *testing.T) {
+	tlsConn := &mock.MockTLSConnection{}
+
+	cn, err := conn.NewConnection(tlsConn.RemoteAddr(), tlsConn, '\n')
+	require.NoError(t, err)
+
+	// Nobody replies, so request is stopped by ctx.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+
+	reply, err := cn.Request(ctx, []byte("ping"))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Nil(t, reply)
+
+	var canceled *conn.CanceledError
+	assert.True(t, errors.As(err, &canceled))
+
+	// Request is in stream, but it's not a plain message anymore.
+	m := conn.NewMessage(cn, len(tlsConn.MWR.Bytes)-1, tlsConn.MWR.Bytes[:len(tlsConn.MWR.Bytes)-1])
+	assert.True(t, m.IsRequest())
+	assert.Equal(t, "ping", string(m.Bytes()))
+
+	// Late reply is dropped. It goes after the request frame: 20-byte header, payload and terminator.
+	_, err = m.Reply([]byte("pong"))
+	require.NoError(t, err)
+
+	reply = conn.NewMessage(cn, 0, tlsConn.MWR.Bytes[len(m.Bytes())+21:len(tlsConn.MWR.Bytes)-1])
+	assert.True(t, reply.IsReply())
+	assert.True(t, cn.Resolve(reply))
+}
+
+func TestConnectionSendContext(t *testing.T) {
+	local, remote := net.Pipe()
+
+	cn, err := conn.NewConnection(local.RemoteAddr(), local, '\n')
+	require.NoError(t, err)
+
+	// Nobody reads the pipe, so write is stopped by deadline of ctx.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	var canceled *conn.CanceledError
+
+	_, err = cn.SendContext(ctx, []byte("one"))
+	assert.True(t, errors.As(err, &canceled))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// Cancelled ctx stops write without deadline.
+	ctx, cancel = context.WithCancel(context.Background())
+
+	go func() {
+		time.Sleep(time.Millisecond * 50)
+		cancel()
+	}()
+
+	_, err = cn.SendContext(ctx, []byte("two"))
+	assert.True(t, errors.As(err, &canceled))
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	// Done ctx doesn't write at all.
+	count, err := cn.SendContext(ctx, []byte("three"))
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, 0, count)
+
+	// Write deadline is reset after send.
+	go func() { _, _ = io.Copy(io.Discard, remote) }()
+
+	count, err = cn.SendContext(context.Background(), []byte("four"))
+	require.NoError(t, err)
+	assert.Equal(t, len("four\n"), count)
+
+	_ = remote.Close()
+}
+
+func TestConnectionSendContextConcurrent(t *testing.T) {
+	local, remote := net.Pipe()
+	defer remote.Close()
+
+	cn, err := conn.NewConnection(local.RemoteAddr(), local, '\n')
+	require.NoError(t, err)
+
+	// Short deadline of one send doesn't stop the other one.
+	short, cancelShort := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancelShort()
+
+	long, cancelLong := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancelLong()
+
+	shortErr := make(chan error, 1)
+
+	go func() {
+		_, err := cn.SendContext(short, []byte("short"))
+		shortErr <- err
+	}()
+
+	go func() {
+		time.Sleep(time.Millisecond * 100)
+		_, _ = io.Copy(io.Discard, remote)
+	}()
+
+	count, err := cn.SendContext(long, []byte("long"))
+	require.NoError(t, err)
+	assert.Equal(t, len("long\n"), count)
+
+	assert.True(t, errors.Is(<-shortErr, context.DeadlineExceeded))
+}
+
+func TestConnectionSendContextWaitsWithContext(t *testing.T) {
+	local, remote := net.Pipe()
+	defer remote.Close()
+
+	cn, err := conn.NewConnection(local.RemoteAddr(), local, '\n')
+	require.NoError(t, err)
+
+	// SendByte is stuck: nobody reads the pipe.
+	stuck := make(chan struct{})
+
+	go func() {
+		_, _ = cn.SendByte([]byte("stuck"))
+		close(stuck)
+	}()
+
+	time.Sleep(time.Millisecond * 20)
+
+	// SendContext doesn't wait for it longer than ctx allows.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	started := time.Now()
+
+	var canceled *conn.CanceledError
+
+	count, err := cn.SendContext(ctx, []byte("one"))
+	assert.True(t, errors.As(err, &canceled))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, 0, count)
+	assert.Less(t, time.Since(started), time.Second)
+
+	_ = local.Close()
+	<-stuck
+}
+
+func TestParseAddress(t *testing.T) {
+	network, address := conn.ParseAddress("unix:///run/app.sock")
+	assert.Equal(t, "unix", network)
+	assert.Equal(t, "/run/app.sock", address)
+
+	network, address = conn.ParseAddress("127.0.0.1:5555")
+	assert.Equal(t, "tcp", network)
+	assert.Equal(t, "127.0.0.1:5555", address)
+}
+
+func TestConnectionTLSStateOfPlainConnection(t *testing.T) {
+	local, remote := net.Pipe()
+	defer local.Close()
+
+	cn, err := conn.NewConnection(remote.RemoteAddr(), remote, '\n')
+	require.NoError(t, err)
+
+	assert.Nil(t, cn.TLSState())
+	assert.Nil(t, cn.PeerIdentity())
+	assert.Empty(t, cn.ServerName())
+
+	snapshot := cn.Snapshot()
+	assert.Equal(t, cn.ID(), snapshot.ID)
+	assert.Nil(t, snapshot.TLS)
+}
+
+func TestTLSPolicyValidate(t *testing.T) {
+	for _, name := range []string{conn.TLSPolicyModern, conn.TLSPolicyCompatible} {
+		policy, err := conn.TLSPolicyPreset(name)
+		require.NoError(t, err)
+		assert.NoError(t, policy.Validate())
+	}
+
+	_, err := conn.TLSPolicyPreset("legacy")
+	assert.True(t, errors.Is(err, conn.ErrInvalidTLSPolicy))
+
+	invalid := map[string]conn.TLSPolicy{
+		"version TLS 1.0 is not supported":       {MinVersion: tls.VersionTLS10},
+		"min version TLS 1.3 is greater":         {MinVersion: tls.VersionTLS13, MaxVersion: tls.VersionTLS12},
+		"not configurable for TLS 1.3":           {MinVersion: tls.VersionTLS13, CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}},
+		"TLS_RSA_WITH_RC4_128_SHA is not":        {CipherSuites: []uint16{tls.TLS_RSA_WITH_RC4_128_SHA}},
+		"curve 1 is not supported":               {CurvePreferences: []tls.CurveID{1}},
+		`ALPN protocol "" must be 1 to 255 byte`: {NextProtos: []string{""}},
+	}
+
+	for message, policy := range invalid {
+		err := policy.Validate()
+		assert.True(t, errors.Is(err, conn.ErrInvalidTLSPolicy), message)
+		assert.Contains(t, err.Error(), message)
+	}
+}
+
+func TestTLSPolicyApply(t *testing.T) {
+	config := &tls.Config{NextProtos: []string{"old"}} //nolint:gosec // test
+
+	conn.TLSPolicy{}.Apply(config)
+	assert.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+	assert.Equal(t, []string{"old"}, config.NextProtos)
+
+	policy := conn.ModernTLSPolicy()
+	policy.NextProtos = []string{"app/1"}
+	policy.SessionTicketsDisabled = true
+	policy.Apply(config)
+
+	assert.Equal(t, uint16(tls.VersionTLS13), config.MinVersion)
+	assert.Equal(t, uint16(tls.VersionTLS13), config.MaxVersion)
+	assert.Equal(t, []string{"app/1"}, config.NextProtos)
+	assert.Equal(t, policy.CurvePreferences, config.CurvePreferences)
+	assert.True(t, config.SessionTicketsDisabled)
+}
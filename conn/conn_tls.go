@@ -0,0 +1,142 @@
+package conn
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/url"
+)
+
+// connectionState returns TLS state of connection and false if connection is not a TLS one.
+func (c *Connection) connectionState() (tls.ConnectionState, bool) {
+	tlsConn, ok := c.tlsConn.(*tls.Conn)
+	if !ok {
+		return tls.ConnectionState{}, false //nolint:exhaustruct // It's OK
+	}
+
+	return tlsConn.ConnectionState(), true
+}
+
+// ServerName returns server name (SNI) client requested during TLS handshake.
+// It's empty if client didn't send it, handshake is not complete yet or connection is not a TLS one.
+func (c *Connection) ServerName() string {
+	state, _ := c.connectionState()
+
+	return state.ServerName
+}
+
+// TLSState is the negotiated state of TLS connection.
+type TLSState struct {
+	// HandshakeComplete is true if handshake is done. Other fields are empty until then.
+	HandshakeComplete bool
+
+	// Version is the TLS version (tls.VersionTLS12, tls.VersionTLS13, ...).
+	Version uint16
+
+	// CipherSuite is the cipher suite ID (tls.TLS_AES_128_GCM_SHA256, ...).
+	CipherSuite uint16
+
+	// NegotiatedProtocol is the application protocol negotiated with ALPN.
+	NegotiatedProtocol string
+
+	// DidResume is true if session was resumed from previous one.
+	DidResume bool
+
+	// ServerName is the server name (SNI) client requested.
+	ServerName string
+
+	// PeerCertificates are certificates peer presented, its own certificate first.
+	PeerCertificates []*x509.Certificate
+}
+
+// VersionName returns name of TLS version, e.g. "TLS 1.3".
+func (s TLSState) VersionName() string { return versionName(s.Version) }
+
+// versionName returns name of TLS version, e.g. "TLS 1.3".
+func versionName(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "TLS 1.0"
+	case tls.VersionTLS11:
+		return "TLS 1.1"
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	default:
+		return fmt.Sprintf("0x%04X", version)
+	}
+}
+
+// CipherSuiteName returns name of cipher suite, e.g. "TLS_AES_128_GCM_SHA256".
+func (s TLSState) CipherSuiteName() string { return tls.CipherSuiteName(s.CipherSuite) }
+
+// TLSState returns negotiated TLS state of connection or nil if connection is not a TLS one.
+func (c *Connection) TLSState() *TLSState {
+	state, ok := c.connectionState()
+	if !ok {
+		return nil
+	}
+
+	return &TLSState{
+		HandshakeComplete:  state.HandshakeComplete,
+		Version:            state.Version,
+		CipherSuite:        state.CipherSuite,
+		NegotiatedProtocol: state.NegotiatedProtocol,
+		DidResume:          state.DidResume,
+		ServerName:         state.ServerName,
+		PeerCertificates:   state.PeerCertificates,
+	}
+}
+
+// PeerIdentity is the identity of remote peer taken from its verified certificate.
+type PeerIdentity struct {
+	// CommonName is the subject common name of peer certificate.
+	CommonName string
+
+	// DNSNames, EmailAddresses, IPAddresses and URIs are subject alternative names of peer certificate.
+	DNSNames       []string
+	EmailAddresses []string
+	IPAddresses    []net.IP
+	URIs           []*url.URL
+
+	// Chain is the certificate chain of peer: its own certificate first and root CA last.
+	// If chain was not verified, it holds certificates peer sent.
+	Chain []*x509.Certificate
+}
+
+// NewPeerIdentity returns identity of peer that presented certificate during handshake with state.
+// It returns nil if peer presented no certificate.
+func NewPeerIdentity(state tls.ConnectionState) *PeerIdentity {
+	chain := state.PeerCertificates
+	if len(state.VerifiedChains) > 0 {
+		chain = state.VerifiedChains[0]
+	}
+
+	if len(chain) == 0 {
+		return nil
+	}
+
+	leaf := chain[0]
+
+	return &PeerIdentity{
+		CommonName:     leaf.Subject.CommonName,
+		DNSNames:       leaf.DNSNames,
+		EmailAddresses: leaf.EmailAddresses,
+		IPAddresses:    leaf.IPAddresses,
+		URIs:           leaf.URIs,
+		Chain:          chain,
+	}
+}
+
+// PeerIdentity returns identity of remote peer verified during TLS handshake (mutual TLS).
+// It returns nil if peer presented no certificate, handshake is not complete yet or connection is not a TLS one.
+func (c *Connection) PeerIdentity() *PeerIdentity {
+	state, ok := c.connectionState()
+	if !ok {
+		return nil
+	}
+
+	return NewPeerIdentity(state)
+}
@@ -1,6 +1,9 @@
 package conn
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // ErrMessageSizeLimit is returned after message length
 // is over server max message size directive.
@@ -16,3 +19,31 @@ var ErrReaderAlreadyClosed = errors.New("reader already closed")
 
 // ErrStreamClosed is returned after io.EOF is appeared in TLS stream.
 var ErrStreamClosed = errors.New("stream closed")
+
+// ErrFrameTooLarge is returned when payload can not be encoded by the framer.
+var ErrFrameTooLarge = errors.New("payload is too large for the frame")
+
+// ErrMalformedFrame is returned when framer is unable to parse frame header.
+var ErrMalformedFrame = errors.New("malformed frame")
+
+// ErrUnknownLengthPrefix is returned when LengthPrefixFramer has unsupported prefix type.
+var ErrUnknownLengthPrefix = errors.New("unknown length prefix")
+
+// ErrNotRequest is returned when client code attempts to reply to a message that is not a request.
+var ErrNotRequest = errors.New("message is not a request")
+
+// ErrDelimiterInHeader is returned when request header contains message delimiter of non-escaping framer.
+// Header has magic "\xffR:", kind byte ('Q' or 'S') and lowercase hex ID.
+var ErrDelimiterInHeader = errors.New("request header contains message delimiter")
+
+// CanceledError is returned when operation is stopped because its context is done.
+// Err is context.Canceled or context.DeadlineExceeded, so errors.Is works with both.
+type CanceledError struct {
+	// Op is the name of operation that was stopped.
+	Op  string
+	Err error
+}
+
+func (e *CanceledError) Error() string { return fmt.Sprintf("%s canceled: %v", e.Op, e.Err) }
+
+func (e *CanceledError) Unwrap() error { return e.Err }
@@ -0,0 +1,245 @@
+package conn
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Framer defines how messages are separated from each other in the stream.
+// Both peers must use framers of the same kind and settings, otherwise they will not understand each other.
+type Framer interface {
+	// Frame wraps payload into a frame that is ready to be written into connection.
+	Frame(payload []byte) ([]byte, error)
+
+	// Split looks for a complete frame at the start of data. It returns message payload and number
+	// of bytes the frame takes in data. Zero advance means that frame is not complete yet and more bytes
+	// should be read. If maxSize > 0, Split returns ErrMessageSizeLimit as soon as it's clear that
+	// the message is larger.
+	Split(data []byte, maxSize int) ([]byte, int, error)
+}
+
+// TerminatorFramer marks message end with a terminator byte or a multi-byte delimiter (e.g. "\r\n").
+// It's the default framing.
+//
+// If escaping is on, first byte of the delimiter and escape byte inside payload are replaced with escape sequences
+// (byte stuffing, as in PPP), so payload may contain any bytes.
+type TerminatorFramer struct {
+	delimiter  []byte
+	escape     bool
+	escapeByte byte
+}
+
+const (
+	// EscapeByte starts escape sequence in payload of escaping TerminatorFramer.
+	EscapeByte byte = 0x7D
+	// AltEscapeByte is used instead of EscapeByte in case terminator clashes with EscapeByte.
+	AltEscapeByte byte = 0x1B
+	// escapeXOR is applied to escaped byte to make it differ from the original one.
+	escapeXOR byte = 0x20
+	// defaultTerminator is used in case delimiter is empty.
+	defaultTerminator byte = '\n'
+)
+
+// NewTerminatorFramer returns framer that ends every message with terminator.
+func NewTerminatorFramer(terminator byte) *TerminatorFramer {
+	return NewDelimiterFramer([]byte{terminator})
+}
+
+// NewEscapingTerminatorFramer returns framer that ends every message with terminator
+// and escapes terminator inside payload.
+func NewEscapingTerminatorFramer(terminator byte) *TerminatorFramer {
+	return NewEscapingDelimiterFramer([]byte{terminator})
+}
+
+// NewDelimiterFramer returns framer that ends every message with delimiter.
+// Empty delimiter is replaced with the newline.
+func NewDelimiterFramer(delimiter []byte) *TerminatorFramer {
+	if len(delimiter) == 0 {
+		delimiter = []byte{defaultTerminator}
+	}
+
+	return &TerminatorFramer{delimiter: append([]byte(nil), delimiter...)}
+}
+
+// NewEscapingDelimiterFramer returns framer that ends every message with delimiter
+// and escapes delimiter inside payload.
+func NewEscapingDelimiterFramer(delimiter []byte) *TerminatorFramer {
+	framer := NewDelimiterFramer(delimiter)
+	framer.escape = true
+	framer.escapeByte = EscapeByte
+
+	// Escape byte and its escaped form must never be equal to the first byte of delimiter,
+	// so delimiter can not start anywhere inside escaped payload.
+	if framer.delimiter[0] == EscapeByte || framer.delimiter[0] == EscapeByte^escapeXOR {
+		framer.escapeByte = AltEscapeByte
+	}
+
+	return framer
+}
+
+// Delimiter returns bytes that mark message end.
+func (f *TerminatorFramer) Delimiter() []byte { return append([]byte(nil), f.delimiter...) }
+
+// Frame appends delimiter to payload and escapes payload if needed.
+func (f *TerminatorFramer) Frame(payload []byte) ([]byte, error) {
+	frame := make([]byte, 0, len(payload)+len(f.delimiter))
+
+	if !f.escape {
+		frame = append(frame, payload...)
+
+		return append(frame, f.delimiter...), nil
+	}
+
+	for _, b := range payload {
+		if b == f.delimiter[0] || b == f.escapeByte {
+			frame = append(frame, f.escapeByte, b^escapeXOR)
+
+			continue
+		}
+
+		frame = append(frame, b)
+	}
+
+	return append(frame, f.delimiter...), nil
+}
+
+// Split returns bytes before first delimiter in data. Delimiter may be split between reads,
+// so it's searched in all bytes collected for the message.
+// Size limit is applied to the bytes as they are in stream, before unescaping.
+func (f *TerminatorFramer) Split(data []byte, maxSize int) ([]byte, int, error) {
+	end := bytes.Index(data, f.delimiter)
+	if end < 0 {
+		// Tail of data may be the beginning of delimiter, it's not counted as message.
+		if length := len(data) - len(f.delimiter) + 1; maxSize > 0 && length > maxSize {
+			return nil, 0, fmt.Errorf("%w (read %v of max %v)", ErrMessageSizeLimit, length, maxSize)
+		}
+
+		return nil, 0, nil
+	}
+
+	if maxSize > 0 && end > maxSize {
+		return nil, 0, fmt.Errorf("%w (read %v of max %v)", ErrMessageSizeLimit, end, maxSize)
+	}
+
+	if !f.escape {
+		return data[:end], end + len(f.delimiter), nil
+	}
+
+	message, err := f.unescape(data[:end])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return message, end + len(f.delimiter), nil
+}
+
+// unescape replaces escape sequences in data with original bytes.
+func (f *TerminatorFramer) unescape(data []byte) ([]byte, error) {
+	message := make([]byte, 0, len(data))
+
+	for i := 0; i < len(data); i++ {
+		if data[i] != f.escapeByte {
+			message = append(message, data[i])
+
+			continue
+		}
+
+		i++
+		if i == len(data) {
+			return nil, fmt.Errorf("%w: escape byte at the end of message", ErrMalformedFrame)
+		}
+
+		message = append(message, data[i]^escapeXOR)
+	}
+
+	return message, nil
+}
+
+// LengthPrefix defines how message length is encoded by LengthPrefixFramer.
+type LengthPrefix int
+
+const (
+	// PrefixFixed32 is the fixed 4-byte big-endian length.
+	PrefixFixed32 LengthPrefix = iota
+	// PrefixUvarint is the unsigned varint length as in encoding/binary.
+	PrefixUvarint
+)
+
+// prefixFixed32Len is the length of PrefixFixed32 header.
+const prefixFixed32Len = 4
+
+// LengthPrefixFramer precedes every message with its length, so payload can contain any bytes.
+type LengthPrefixFramer struct {
+	prefix LengthPrefix
+}
+
+// NewLengthPrefixFramer returns framer that encodes message length using prefix.
+func NewLengthPrefixFramer(prefix LengthPrefix) *LengthPrefixFramer {
+	return &LengthPrefixFramer{prefix: prefix}
+}
+
+// Frame puts length header before payload.
+func (f *LengthPrefixFramer) Frame(payload []byte) ([]byte, error) {
+	switch f.prefix {
+	case PrefixFixed32:
+		if uint64(len(payload)) > math.MaxUint32 {
+			return nil, fmt.Errorf("%w (length %v)", ErrFrameTooLarge, len(payload))
+		}
+
+		frame := make([]byte, prefixFixed32Len, prefixFixed32Len+len(payload))
+		binary.BigEndian.PutUint32(frame, uint32(len(payload)))
+
+		return append(frame, payload...), nil
+	case PrefixUvarint:
+		frame := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(payload))
+		n := binary.PutUvarint(frame, uint64(len(payload)))
+
+		return append(frame[:n], payload...), nil
+	default:
+		return nil, fmt.Errorf("%w (%v)", ErrUnknownLengthPrefix, f.prefix)
+	}
+}
+
+// Split reads length header and returns payload once it's fully in data.
+// Message size is checked right after header is read, before waiting for the payload.
+func (f *LengthPrefixFramer) Split(data []byte, maxSize int) ([]byte, int, error) {
+	var (
+		length    uint64
+		headerLen int
+	)
+
+	switch f.prefix {
+	case PrefixFixed32:
+		if len(data) < prefixFixed32Len {
+			return nil, 0, nil
+		}
+
+		length = uint64(binary.BigEndian.Uint32(data))
+		headerLen = prefixFixed32Len
+	case PrefixUvarint:
+		length, headerLen = binary.Uvarint(data)
+		if headerLen == 0 {
+			return nil, 0, nil
+		}
+
+		if headerLen < 0 {
+			return nil, 0, fmt.Errorf("%w: length overflows uint64", ErrMalformedFrame)
+		}
+	default:
+		return nil, 0, fmt.Errorf("%w (%v)", ErrUnknownLengthPrefix, f.prefix)
+	}
+
+	if maxSize > 0 && length > uint64(maxSize) {
+		return nil, 0, fmt.Errorf("%w (length %v of max %v)", ErrMessageSizeLimit, length, maxSize)
+	}
+
+	if uint64(len(data)-headerLen) < length {
+		return nil, 0, nil
+	}
+
+	end := headerLen + int(length)
+
+	return data[headerLen:end], end, nil
+}
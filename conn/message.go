@@ -5,10 +5,25 @@ type Message struct {
 	conn   *Connection
 	length int
 	bytes  []byte
+
+	// kind shows if message is a plain one, a request or a reply.
+	kind messageKind
+
+	// requestID is the correlation ID of request or reply.
+	requestID uint64
 }
 
+// NewMessage creates message from bytes read. If bytes have request or reply header,
+// it's removed from message bytes and saved as message correlation ID, length is set to payload length.
 func NewMessage(conn *Connection, length int, bytes []byte) *Message {
-	return &Message{conn: conn, length: length, bytes: bytes}
+	message := &Message{conn: conn, length: length, bytes: bytes}
+	message.kind, message.requestID, message.bytes = parseRequestHeader(bytes)
+
+	if message.kind != kindPlain {
+		message.length = len(message.bytes)
+	}
+
+	return message
 }
 
 // Bytes returns message bytes.
@@ -19,3 +34,24 @@ func (m *Message) Length() int { return m.length }
 
 // Conn returns pointer to connection in which message was received.
 func (m *Message) Conn() *Connection { return m.conn }
+
+// IsRequest returns true if remote waits for reply to the message.
+func (m *Message) IsRequest() bool { return m.kind == kindRequest }
+
+// IsReply returns true if message is a reply to request.
+func (m *Message) IsReply() bool { return m.kind == kindReply }
+
+// RequestID returns correlation ID of request or reply. It's zero for plain messages.
+func (m *Message) RequestID() uint64 { return m.requestID }
+
+// Reply sends payload as a reply to the message. See Connection.Reply.
+func (m *Message) Reply(payload []byte) (int, error) { return m.conn.Reply(m, payload) }
+
+// WithBytes returns copy of the message that holds b instead of original bytes.
+// Request ID and kind are kept, so reply can be sent to the new message.
+func (m *Message) WithBytes(b []byte) *Message {
+	message := *m
+	message.bytes = b
+
+	return &message
+}
@@ -0,0 +1,158 @@
+package conn
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidTLSPolicy is returned when TLSPolicy has settings that can't be used.
+var ErrInvalidTLSPolicy = errors.New("invalid TLS policy")
+
+const (
+	// TLSPolicyModern is the name of ModernTLSPolicy preset.
+	TLSPolicyModern = "modern"
+	// TLSPolicyCompatible is the name of CompatibleTLSPolicy preset.
+	TLSPolicyCompatible = "compatible"
+)
+
+// maxALPNProtocolLength is the max length of ALPN protocol name allowed by RFC 7301.
+const maxALPNProtocolLength = 255
+
+// TLSPolicy sets TLS parameters peer is allowed to negotiate. Zero values keep defaults of crypto/tls,
+// except MinVersion which is TLS 1.2 by default.
+type TLSPolicy struct {
+	// MinVersion and MaxVersion limit TLS versions (tls.VersionTLS12 or tls.VersionTLS13).
+	MinVersion uint16
+	MaxVersion uint16
+
+	// CipherSuites limits cipher suites of TLS 1.2. Cipher suites of TLS 1.3 are not configurable.
+	// Only secure suites (see tls.CipherSuites) are allowed.
+	CipherSuites []uint16
+
+	// CurvePreferences sets elliptic curves used in key exchange, in preference order.
+	CurvePreferences []tls.CurveID
+
+	// NextProtos are ALPN protocols, in preference order.
+	NextProtos []string
+
+	// SessionTicketsDisabled turns off session resumption by tickets.
+	SessionTicketsDisabled bool
+}
+
+// ModernTLSPolicy returns policy that allows TLS 1.3 only.
+func ModernTLSPolicy() TLSPolicy {
+	return TLSPolicy{ //nolint:exhaustruct // It's OK
+		MinVersion:       tls.VersionTLS13,
+		MaxVersion:       tls.VersionTLS13,
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384},
+	}
+}
+
+// CompatibleTLSPolicy returns policy that allows TLS 1.2 with forward secret AEAD cipher suites and TLS 1.3.
+func CompatibleTLSPolicy() TLSPolicy {
+	return TLSPolicy{ //nolint:exhaustruct // It's OK
+		MinVersion: tls.VersionTLS12,
+		MaxVersion: tls.VersionTLS13,
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384},
+	}
+}
+
+// TLSPolicyPreset returns preset policy by name: TLSPolicyModern or TLSPolicyCompatible.
+func TLSPolicyPreset(name string) (TLSPolicy, error) {
+	switch name {
+	case TLSPolicyModern:
+		return ModernTLSPolicy(), nil
+	case TLSPolicyCompatible:
+		return CompatibleTLSPolicy(), nil
+	default:
+		return TLSPolicy{}, fmt.Errorf("%w: unknown preset %q", ErrInvalidTLSPolicy, name) //nolint:exhaustruct // It's OK
+	}
+}
+
+// Validate returns error that wraps ErrInvalidTLSPolicy and describes the first setting that can't be used.
+func (p TLSPolicy) Validate() error { //nolint:cyclop // It's OK
+	for _, version := range []uint16{p.MinVersion, p.MaxVersion} {
+		if version != 0 && version != tls.VersionTLS12 && version != tls.VersionTLS13 {
+			return fmt.Errorf("%w: version %s is not supported, use TLS 1.2 or TLS 1.3",
+				ErrInvalidTLSPolicy, versionName(version))
+		}
+	}
+
+	if p.MinVersion != 0 && p.MaxVersion != 0 && p.MinVersion > p.MaxVersion {
+		return fmt.Errorf("%w: min version %s is greater than max version %s",
+			ErrInvalidTLSPolicy, versionName(p.MinVersion), versionName(p.MaxVersion))
+	}
+
+	if len(p.CipherSuites) > 0 && p.MinVersion == tls.VersionTLS13 {
+		return fmt.Errorf("%w: cipher suites are not configurable for TLS 1.3 only policy", ErrInvalidTLSPolicy)
+	}
+
+	secure := make(map[uint16]bool)
+	for _, suite := range tls.CipherSuites() {
+		secure[suite.ID] = true
+	}
+
+	for _, suite := range p.CipherSuites {
+		if !secure[suite] {
+			return fmt.Errorf("%w: cipher suite %s is not allowed", ErrInvalidTLSPolicy, tls.CipherSuiteName(suite))
+		}
+	}
+
+	for _, curve := range p.CurvePreferences {
+		switch curve { //nolint:exhaustive // Other curves are not supported
+		case tls.X25519, tls.CurveP256, tls.CurveP384, tls.CurveP521:
+		default:
+			return fmt.Errorf("%w: curve %d is not supported", ErrInvalidTLSPolicy, curve)
+		}
+	}
+
+	for _, proto := range p.NextProtos {
+		if proto == "" || len(proto) > maxALPNProtocolLength {
+			return fmt.Errorf("%w: ALPN protocol %q must be 1 to %d bytes long",
+				ErrInvalidTLSPolicy, proto, maxALPNProtocolLength)
+		}
+	}
+
+	return nil
+}
+
+// Apply sets policy settings into config. Settings with zero values are not changed,
+// except MinVersion which is set to TLS 1.2 if neither policy nor config have it.
+func (p TLSPolicy) Apply(config *tls.Config) {
+	if p.MinVersion != 0 {
+		config.MinVersion = p.MinVersion
+	}
+
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+
+	if p.MaxVersion != 0 {
+		config.MaxVersion = p.MaxVersion
+	}
+
+	if len(p.CipherSuites) > 0 {
+		config.CipherSuites = p.CipherSuites
+	}
+
+	if len(p.CurvePreferences) > 0 {
+		config.CurvePreferences = p.CurvePreferences
+	}
+
+	if len(p.NextProtos) > 0 {
+		config.NextProtos = p.NextProtos
+	}
+
+	if p.SessionTicketsDisabled {
+		config.SessionTicketsDisabled = true
+	}
+}
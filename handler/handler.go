@@ -0,0 +1,49 @@
+// Package handler holds message handler types shared by server.Server, client.Client and router.Router,
+// so client apps don't link the server package to use them.
+package handler
+
+import (
+	"errors"
+
+	"github.com/lazybark/go-tls-server/conn"
+)
+
+// ErrHandlerPanic is sent into error channel when handler panics.
+var ErrHandlerPanic = errors.New("handler panic")
+
+// Handler responds to a received message.
+type Handler interface {
+	ServeMessage(w ResponseWriter, m *conn.Message)
+}
+
+// HandlerFunc allows to use ordinary functions as handlers.
+type HandlerFunc func(w ResponseWriter, m *conn.Message)
+
+// ServeMessage calls f(w, m).
+func (f HandlerFunc) ServeMessage(w ResponseWriter, m *conn.Message) { f(w, m) }
+
+// ResponseWriter is used by handler to answer to the message.
+type ResponseWriter interface {
+	// Write sends b into connection the message came from. If the message is a request,
+	// b is sent as reply to it.
+	Write(b []byte) (int, error)
+
+	// WriteString converts s into byte slice and calls to Write.
+	WriteString(s string) (int, error)
+
+	// Conn returns connection the message came from.
+	Conn() *conn.Connection
+}
+
+// Middleware wraps handler to run common logic before and/or after it.
+type Middleware func(Handler) Handler
+
+// Chain wraps handler into middlewares. First middleware is the outermost one,
+// so it runs first for every message.
+func Chain(h Handler, middlewares ...Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+
+	return h
+}
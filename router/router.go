@@ -0,0 +1,169 @@
+// Package router dispatches messages to handlers by command name, prefix or message kind byte.
+// Router implements handler.Handler (server.Handler is the same type), so it can be passed to server.Server.Serve and client.Client.Serve.
+package router
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/lazybark/go-tls-server/conn"
+	"github.com/lazybark/go-tls-server/handler"
+)
+
+const (
+	// DefaultSeparator separates command name from the rest of the message.
+	DefaultSeparator byte = ' '
+
+	// DefaultUnknownReply is sent to remote if no handler matches the message and fallback is not set.
+	DefaultUnknownReply = "unknown command"
+)
+
+// Router picks handler for every message in the following order:
+// exact command, longest prefix, message kind (first byte), fallback.
+// If nothing matches, unknown reply is sent to remote.
+type Router struct {
+	// separator separates command name from the rest of the message.
+	separator byte
+
+	// commands holds handlers by command name.
+	commands map[string]handler.Handler
+
+	// prefixes holds handlers by message prefix, longest prefixes go first.
+	prefixes []prefixRoute
+
+	// kinds holds handlers by the first byte of message.
+	kinds map[byte]handler.Handler
+
+	// fallback handles messages that did not match any route.
+	fallback handler.Handler
+
+	// unknownReply is sent in case there is no route and no fallback. Empty means no reply.
+	unknownReply func(m *conn.Message) []byte
+
+	mu sync.RWMutex
+}
+
+type prefixRoute struct {
+	prefix  []byte
+	handler handler.Handler
+}
+
+// New returns router with DefaultSeparator and DefaultUnknownReply.
+func New() *Router {
+	return &Router{
+		separator:    DefaultSeparator,
+		commands:     make(map[string]handler.Handler),
+		kinds:        make(map[byte]handler.Handler),
+		unknownReply: func(*conn.Message) []byte { return []byte(DefaultUnknownReply) },
+	}
+}
+
+// SetSeparator sets byte that separates command name from the rest of the message.
+func (r *Router) SetSeparator(separator byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.separator = separator
+}
+
+// SetUnknownReply sets function that makes reply to a message without route. Nil means no reply.
+func (r *Router) SetUnknownReply(reply func(m *conn.Message) []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.unknownReply = reply
+}
+
+// Handle registers handler for command. Command is the start of the message up to separator
+// or the whole message if there is no separator. Handler gets message without command and separator.
+func (r *Router) Handle(command string, handler handler.Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.commands[command] = handler
+}
+
+// HandleFunc registers function as handler for command.
+func (r *Router) HandleFunc(command string, f func(w handler.ResponseWriter, m *conn.Message)) {
+	r.Handle(command, handler.HandlerFunc(f))
+}
+
+// HandlePrefix registers handler for all messages that start with prefix. Handler gets message as is.
+func (r *Router) HandlePrefix(prefix string, handler handler.Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.prefixes = append(r.prefixes, prefixRoute{prefix: []byte(prefix), handler: handler})
+	sort.SliceStable(r.prefixes, func(i, j int) bool { return len(r.prefixes[i].prefix) > len(r.prefixes[j].prefix) })
+}
+
+// HandleKind registers handler for messages which first byte is kind. It's useful for binary
+// protocols with typed messages. Handler gets message without the kind byte.
+func (r *Router) HandleKind(kind byte, handler handler.Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.kinds[kind] = handler
+}
+
+// Fallback sets handler for messages that did not match any route. Handler gets message as is.
+func (r *Router) Fallback(handler handler.Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.fallback = handler
+}
+
+// ServeMessage passes message to the matching handler.
+func (r *Router) ServeMessage(w handler.ResponseWriter, m *conn.Message) {
+	handler, message := r.match(m)
+	if handler != nil {
+		handler.ServeMessage(w, message)
+
+		return
+	}
+
+	r.mu.RLock()
+	unknownReply := r.unknownReply
+	r.mu.RUnlock()
+
+	if unknownReply == nil {
+		return
+	}
+
+	if reply := unknownReply(m); len(reply) > 0 {
+		_, _ = w.Write(reply)
+	}
+}
+
+// match returns handler for m and message that should be passed to it.
+func (r *Router) match(m *conn.Message) (handler.Handler, *conn.Message) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	b := m.Bytes()
+
+	command, rest := b, []byte{}
+	if i := bytes.IndexByte(b, r.separator); i >= 0 {
+		command, rest = b[:i], b[i+1:]
+	}
+
+	if handler, ok := r.commands[string(command)]; ok {
+		return handler, m.WithBytes(rest)
+	}
+
+	for _, route := range r.prefixes {
+		if bytes.HasPrefix(b, route.prefix) {
+			return route.handler, m
+		}
+	}
+
+	if len(b) > 0 {
+		if handler, ok := r.kinds[b[0]]; ok {
+			return handler, m.WithBytes(b[1:])
+		}
+	}
+
+	return r.fallback, m
+}
@@ -0,0 +1,81 @@
+package router_test
+
+import (
+	"testing"
+
+	"github.com/lazybark/go-helpers/mock"
+	"github.com/lazybark/go-tls-server/conn"
+	"github.com/lazybark/go-tls-server/router"
+	"github.com/lazybark/go-tls-server/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testWriter struct {
+	c       *conn.Connection
+	written []string
+}
+
+func (w *testWriter) Write(b []byte) (int, error) {
+	w.written = append(w.written, string(b))
+
+	return len(b), nil
+}
+
+func (w *testWriter) WriteString(s string) (int, error) { return w.Write([]byte(s)) }
+
+func (w *testWriter) Conn() *conn.Connection { return w.c }
+
+func TestRouter(t *testing.T) {
+	tlsConn := &mock.MockTLSConnection{}
+	cn, err := conn.NewConnection(tlsConn.RemoteAddr(), tlsConn, '\n')
+	require.NoError(t, err)
+
+	route := func(name string) server.HandlerFunc {
+		return func(w server.ResponseWriter, m *conn.Message) {
+			_, _ = w.WriteString(name + ":" + string(m.Bytes()))
+		}
+	}
+
+	r := router.New()
+	r.HandleFunc("GET", route("get"))
+	r.Handle("PING", route("ping"))
+	r.HandlePrefix("/api", route("api"))
+	r.HandlePrefix("/api/v2", route("api2"))
+	r.HandleKind(0x01, route("kind"))
+
+	w := &testWriter{c: cn}
+	serve := func(s string) { r.ServeMessage(w, conn.NewMessage(cn, len(s), []byte(s))) }
+
+	serve("GET key")
+	serve("PING")
+	serve("/api/v2/users")
+	serve("/api/users")
+	serve("\x01data")
+	serve("DELETE key")
+
+	assert.Equal(t, []string{
+		"get:key",
+		"ping:",
+		"api2:/api/v2/users",
+		"api:/api/users",
+		"kind:data",
+		router.DefaultUnknownReply,
+	}, w.written)
+
+	// Unknown reply is configurable.
+	w.written = nil
+	r.SetUnknownReply(func(m *conn.Message) []byte { return []byte("ERR " + string(m.Bytes())) })
+	serve("DELETE key")
+	r.SetUnknownReply(nil)
+	serve("DELETE key")
+	assert.Equal(t, []string{"ERR DELETE key"}, w.written)
+
+	// Fallback gets everything that did not match.
+	w.written = nil
+	r.SetSeparator(':')
+	r.Fallback(route("fallback"))
+	serve("GET key")
+	serve("GET:key")
+	assert.Equal(t, []string{"fallback:GET key", "get:key"}, w.written)
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"crypto/tls"
 	"errors"
 	"fmt"
@@ -10,92 +11,312 @@ import (
 )
 
 // Listen runs listener interface implementations and accepts connections.
-func (s *Server) Listen(port string) error { //nolint:cyclop // in TODOs
-	listener, err := tls.Listen("tcp", ":"+port, s.tlsConfig)
+// It listens on all interfaces, use ListenAddr or ServeListener to bind specific addresses.
+// Server can listen again after it was stopped.
+func (s *Server) Listen(port string) error {
+	err := s.listenAddr([]string{":" + port})
 	if err != nil {
-		return s.FormatError(fmt.Errorf("[Listen] error listening: %w", err))
+		return s.FormatError(fmt.Errorf("[Listen] %w", err))
 	}
 
-	s.SetActive(true)
+	return nil
+}
 
-	s.listener = listener
+// ListenAddr listens on every address of addrs (host:port or "unix:///path.sock") at once and accepts connections.
+// All addresses share one connection pool and stats. If any address can't be listened on,
+// none of them are.
+//
+// Socket file of Unix domain socket is removed on Stop. Stale socket file (nobody listens on it) is
+// replaced, but socket in use makes ListenAddr return ErrSocketInUse.
+func (s *Server) ListenAddr(addrs ...string) error {
+	err := s.listenAddr(addrs)
+	if err != nil {
+		return s.FormatError(fmt.Errorf("[ListenAddr] %w", err))
+	}
 
-	go func() {
-		for {
-			select {
-			case <-s.ctx.Done():
-				return
-			default:
-				// Accept the connection.
-				tlsConn, err := listener.Accept()
-
-				// The problem is that a listener can be closed during the listening. Then we get net.ErrClosed.
-				// In this case we always ignore it, because doesn't matter why it's closed: this function is not for err processing.
-				// Error was handled somewhere else already. Or server was simply terminated.
-				if err != nil {
-					if errors.Is(err, net.ErrClosed) {
-						continue
-					}
-
-					if !s.sConfig.SuppressErrors {
-						s.errChan <- s.FormatError(fmt.Errorf("[Listen] error accepting connection: %w", err))
-					}
-				}
+	return nil
+}
 
-				// Just a precaution to avoid nil pointer dereference.
-				if tlsConn == nil {
-					continue
-				}
+// ServeListener accepts connections from every listener of listeners. Listeners should accept plain
+// connections (e.g. bound to specific IP, wrapped or handed over by systemd): server wraps them
+// with its own TLS config (Unix socket listeners are not wrapped if PlainUnixSockets is set). All listeners share one connection pool and stats and are closed by Stop or Shutdown.
+func (s *Server) ServeListener(listeners ...net.Listener) error {
+	err := s.serveListeners(listeners)
+	if err != nil {
+		return s.FormatError(fmt.Errorf("[ServeListener] %w", err))
+	}
+
+	return nil
+}
+
+// listenAddr opens listeners for addrs and starts a run with them.
+func (s *Server) listenAddr(addrs []string) error {
+	if len(addrs) == 0 {
+		return ErrNoListeners
+	}
+
+	listeners := make([]net.Listener, 0, len(addrs))
+
+	for _, addr := range addrs {
+		var (
+			listener net.Listener
+			err      error
+		)
+
+		network, address := conn.ParseAddress(addr)
+		if network == "unix" {
+			listener, err = listenUnix(address)
+		} else {
+			listener, err = net.Listen(network, address)
+		}
+
+		if err != nil {
+			closeListeners(listeners)
+
+			return fmt.Errorf("error listening on %s: %w", addr, err)
+		}
+
+		listeners = append(listeners, listener)
+	}
+
+	err := s.serveListeners(listeners)
+	if err != nil {
+		closeListeners(listeners)
+
+		return err
+	}
+
+	return nil
+}
+
+// serveListeners wraps listeners with TLS (except Unix sockets if PlainUnixSockets is set)
+// and starts a run with them.
+func (s *Server) serveListeners(listeners []net.Listener) error {
+	if len(listeners) == 0 {
+		return ErrNoListeners
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tlsListeners := make([]net.Listener, len(listeners))
+
+	for i, listener := range listeners {
+		if s.sConfig.PlainUnixSockets && isUnixListener(listener) {
+			tlsListeners[i] = listener
+
+			continue
+		}
+
+		tlsListeners[i] = tls.NewListener(listener, s.tlsConfig)
+	}
+
+	return s.startRun(tlsListeners)
+}
+
+// Addrs returns addresses server listens on in current run.
+func (s *Server) Addrs() []net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	addrs := make([]net.Addr, len(s.listeners))
+	for i, listener := range s.listeners {
+		addrs[i] = listener.Addr()
+	}
+
+	return addrs
+}
+
+// closeListeners closes every listener of listeners.
+func closeListeners(listeners []net.Listener) {
+	for _, listener := range listeners {
+		_ = listener.Close()
+	}
+}
+
+// accept accepts connections until listener is closed or server is stopped.
+// Every connection completes TLS handshake in its own routine, so slow peers don't stall accepting.
+func (s *Server) accept(ctx context.Context, listener net.Listener, closing chan struct{}) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			// Accept the connection.
+			netConn, err := listener.Accept()
 
-				connection, err := conn.NewConnection(tlsConn.RemoteAddr(), tlsConn, s.sConfig.MessageTerminator)
-				if err != nil && !s.sConfig.SuppressErrors {
-					s.errChan <- s.FormatError(fmt.Errorf("[Listen] error making connection for %v: %w", tlsConn.RemoteAddr(), err))
+			// The problem is that a listener can be closed during the listening. Then we get net.ErrClosed.
+			// In this case we always stop silently, because doesn't matter why it's closed: this function is not for err processing.
+			// Error was handled somewhere else already. Or server was simply terminated.
+			if err != nil {
+				if errors.Is(err, net.ErrClosed) {
+					return
 				}
 
-				// Add to pool.
-				s.addToPool(connection)
-				// Notify outer routine.
-				s.connChan <- connection
-				// Wait for new messages.
-				go s.receive(connection)
+				s.sendError(fmt.Errorf("[Listen] error accepting connection: %w", err))
 			}
+
+			// Just a precaution to avoid nil pointer dereference.
+			if netConn == nil {
+				continue
+			}
+
+			if !s.acquireHandshake(closing) {
+				_ = netConn.Close()
+
+				return
+			}
+
+			go s.handshake(ctx, netConn, closing)
 		}
-	}()
+	}
+}
 
-	return nil
+// handshake completes TLS handshake of netConn within HandshakeTimeout, then puts connection into pool
+// and notifies outer routine. Connection that failed handshake is closed.
+func (s *Server) handshake(ctx context.Context, netConn net.Conn, closing chan struct{}) {
+	err := s.handshakeTLS(ctx, netConn)
+	s.releaseHandshake()
+
+	if err != nil {
+		s.addHandshakeErrors(1)
+		s.sendError(fmt.Errorf("[Listen] error handshaking with %v: %w", netConn.RemoteAddr(), err))
+
+		_ = netConn.Close()
+
+		return
+	}
+
+	connection, err := conn.NewConnection(netConn.RemoteAddr(), netConn, s.sConfig.MessageTerminator)
+	if err != nil {
+		s.sendError(fmt.Errorf("[Listen] error making connection for %v: %w", netConn.RemoteAddr(), err))
+
+		_ = netConn.Close()
+
+		return
+	}
+
+	connection.SetFramer(s.sConfig.Framer)
+
+	// Add to pool.
+	s.addToPool(connection)
+
+	// Stop closes connections that are in pool already, the ones added after that are closed here.
+	if isClosed(closing) {
+		_ = connection.Abort()
+
+		return
+	}
+
+	// Notify outer routine. Connection that came during stopping is not needed anymore.
+	select {
+	case s.connChan <- connection:
+	case <-closing:
+		_ = connection.Abort()
+
+		return
+	}
+	// Wait for new messages.
+	go s.receive(connection)
+}
+
+// handshakeTLS runs TLS handshake of netConn within HandshakeTimeout.
+// Plain connections (Unix sockets with PlainUnixSockets) have no handshake.
+func (s *Server) handshakeTLS(ctx context.Context, netConn net.Conn) error {
+	tlsConn, ok := netConn.(*tls.Conn)
+	if !ok {
+		return nil
+	}
+
+	if s.sConfig.HandshakeTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, s.sConfig.HandshakeTimeout)
+		defer cancel()
+	}
+
+	return tlsConn.HandshakeContext(ctx)
+}
+
+// acquireHandshake waits for a free handshake slot (see Config.MaxHandshakes).
+// It returns false if server was stopped while waiting.
+func (s *Server) acquireHandshake(closing chan struct{}) bool {
+	if s.handshakes == nil {
+		return true
+	}
+
+	select {
+	case s.handshakes <- struct{}{}:
+		return true
+	case <-closing:
+		return false
+	}
+}
+
+// releaseHandshake frees handshake slot taken by acquireHandshake.
+func (s *Server) releaseHandshake() {
+	if s.handshakes != nil {
+		<-s.handshakes
+	}
 }
 
 // receive endlessly reads incoming stream and delivers messages to receivers outside server routine.
-// It uses ReadWithContext, so execution can be manually stopped by calling c.cancel on specific connection.
+// It uses ReadMessage, so execution can be manually stopped by calling c.cancel on specific connection.
 // In that case (or if any error occurs) method will trigger s.CloseConnection to break connection too.
 func (s *Server) receive(connection *conn.Connection) {
+	// This is the only routine that writes messages of the connection.
+	defer connection.CloseMessageChan()
+	// Closed connection is not a member of any group anymore.
+	defer s.leaveAll(connection)
+
 	for {
 		if connection.Closed() {
 			return
 		}
 
-		bytes, bytesCount, err := connection.ReadWithContext(
-			s.sConfig.BufferSize,
-			s.sConfig.MaxMessageSize,
-			s.sConfig.MessageTerminator,
-		)
+		bytes, bytesCount, err := connection.ReadMessage(s.sConfig.BufferSize, s.sConfig.MaxMessageSize)
 		if err != nil {
-			if !s.sConfig.SuppressErrors {
-				s.errChan <- s.FormatError(fmt.Errorf("[receive] error reading from %s: %w", connection.ID(), err))
-			}
+			s.sendError(fmt.Errorf("[receive] error reading from %s: %w", connection.ID(), err))
 
 			err := s.CloseConnection(connection)
-			if err != nil && !s.sConfig.SuppressErrors {
-				s.errChan <- s.FormatError(fmt.Errorf("[receive] error closing connection: %w", err))
+			if err != nil {
+				s.sendError(fmt.Errorf("[receive] error closing connection: %w", err))
 			}
 
 			return
 		}
 
-		// Check in case we read 0 bytes.
-		if bytesCount > 0 {
-			s.addRecBytes(bytesCount)
-			connection.MessageChanWrite() <- conn.NewMessage(connection, bytesCount, bytes)
+		s.addRecBytes(bytesCount)
+
+		// Nil means reading was stopped without a message. Message may also come from bytes
+		// left after previous one, in that case 0 bytes were read.
+		if bytes != nil {
+			message := conn.NewMessage(connection, len(bytes), bytes)
+			// Replies go straight to requests waiting for them.
+			if !connection.Resolve(message) && !s.deliver(connection, message) {
+				return
+			}
+		}
+	}
+}
+
+// deliver passes message to the routine that reads connection messages. Message is counted as in flight
+// until it's taken or, in case of Serve, until handler returns. It returns false if connection was closed
+// before message was taken.
+func (s *Server) deliver(connection *conn.Connection, message *conn.Message) bool {
+	connection.AddInFlight(1)
+
+	select {
+	case connection.MessageChanWrite() <- message:
+		// Served connection releases message after handler is done.
+		if !connection.Served() {
+			connection.AddInFlight(-1)
 		}
+
+		return true
+	case <-connection.Done():
+		connection.AddInFlight(-1)
+
+		return false
 	}
 }
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/lazybark/go-tls-server/conn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dialTestServer connects to srv at addr (host:port or "unix:///path.sock") and returns client side and accepted server side of connection.
+// Server waits until connection is taken, so it's accepted concurrently.
+func dialTestServer(t *testing.T, srv *Server, addr string, config *tls.Config) (*tls.Conn, *conn.Connection) {
+	t.Helper()
+
+	if config == nil {
+		config = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // test
+	}
+
+	connections := make(chan *conn.Connection, 1)
+
+	go func() {
+		connection, _ := srv.AcceptConnection()
+		connections <- connection
+	}()
+
+	network, address := conn.ParseAddress(addr)
+
+	client, err := tls.Dial(network, address, config)
+	require.NoError(t, err)
+
+	connection := <-connections
+	require.NotNil(t, connection)
+
+	return client, connection
+}
+
+func TestListenAddr(t *testing.T) {
+	srv := newTestTLSServer(t)
+
+	assert.True(t, errors.Is(srv.ListenAddr(), ErrNoListeners))
+	assert.True(t, errors.Is(srv.ServeListener(), ErrNoListeners))
+
+	require.NoError(t, srv.ListenAddr("127.0.0.1:0", "127.0.0.1:0"))
+
+	addrs := srv.Addrs()
+	require.Len(t, addrs, 2)
+
+	// Both addresses share one pool.
+	for _, addr := range addrs {
+		client, _ := dialTestServer(t, srv, addr.String(), nil)
+		defer client.Close()
+	}
+
+	srv.connPoolMutex.RLock()
+	assert.Len(t, srv.connPool, 2)
+	srv.connPoolMutex.RUnlock()
+
+	require.NoError(t, srv.Stop())
+	assert.Empty(t, srv.Addrs())
+
+	for _, addr := range addrs {
+		_, err := net.Dial("tcp", addr.String())
+		assert.Error(t, err)
+	}
+}
+
+func TestListenAddrFailure(t *testing.T) {
+	srv := newTestTLSServer(t)
+
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer busy.Close()
+
+	free, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	freeAddr := free.Addr().String()
+	require.NoError(t, free.Close())
+
+	// Second address is taken, so first one is released too.
+	assert.Error(t, srv.ListenAddr(freeAddr, busy.Addr().String()))
+	assert.Equal(t, StateNew, srv.State())
+
+	free, err = net.Listen("tcp", freeAddr)
+	require.NoError(t, err)
+	require.NoError(t, free.Close())
+}
+
+func TestServeListener(t *testing.T) {
+	srv := newTestTLSServer(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	require.NoError(t, srv.ServeListener(listener))
+	assert.Equal(t, listener.Addr().String(), srv.Addrs()[0].String())
+
+	// Server wraps plain listener with TLS.
+	client, connection := dialTestServer(t, srv, listener.Addr().String(), nil)
+	defer client.Close()
+
+	_, err = client.Write([]byte("hello\n"))
+	require.NoError(t, err)
+
+	message, err := connection.GetMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(message.Bytes()))
+
+	// Listening server can't serve one more listener.
+	assert.True(t, errors.Is(srv.ServeListener(listener), ErrInvalidTransition))
+
+	require.NoError(t, srv.Stop())
+}
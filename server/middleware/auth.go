@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"github.com/lazybark/go-tls-server/conn"
+	"github.com/lazybark/go-tls-server/server"
+)
+
+// Auth passes message to handler only if check returns nil. Otherwise reject is sent to remote
+// (if it's not empty) and error is counted in connection errors.
+func Auth(check func(m *conn.Message) error, reject []byte) server.Middleware {
+	return func(next server.Handler) server.Handler {
+		return server.HandlerFunc(func(w server.ResponseWriter, m *conn.Message) {
+			if err := check(m); err != nil {
+				m.Conn().AddErrors(1)
+
+				if len(reject) > 0 {
+					_, _ = w.Write(reject)
+				}
+
+				return
+			}
+
+			next.ServeMessage(w, m)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/lazybark/go-tls-server/conn"
+	"github.com/lazybark/go-tls-server/server"
+)
+
+// gzipMagic starts every gzip stream.
+const gzipMagic = "\x1f\x8b"
+
+// Decompress unpacks gzip-compressed messages before passing them to handler. Other messages are passed as is.
+// If maxSize > 0, messages that unpack into more than maxSize bytes are dropped.
+// Messages that can not be unpacked are dropped and counted in connection errors.
+func Decompress(maxSize int) server.Middleware {
+	return func(next server.Handler) server.Handler {
+		return server.HandlerFunc(func(w server.ResponseWriter, m *conn.Message) {
+			if !bytes.HasPrefix(m.Bytes(), []byte(gzipMagic)) {
+				next.ServeMessage(w, m)
+
+				return
+			}
+
+			unpacked, err := gunzip(m.Bytes(), maxSize)
+			if err != nil {
+				m.Conn().AddErrors(1)
+
+				return
+			}
+
+			next.ServeMessage(w, m.WithBytes(unpacked))
+		})
+	}
+}
+
+// gunzip unpacks b reading not more than maxSize bytes.
+func gunzip(b []byte, maxSize int) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("[gunzip] %w", err)
+	}
+	defer reader.Close()
+
+	var limited io.Reader = reader
+	if maxSize > 0 {
+		limited = io.LimitReader(reader, int64(maxSize)+1)
+	}
+
+	unpacked, err := io.ReadAll(limited)
+	if err != nil {
+		return nil, fmt.Errorf("[gunzip] %w", err)
+	}
+
+	if maxSize > 0 && len(unpacked) > maxSize {
+		return nil, fmt.Errorf("[gunzip] %w", conn.ErrMessageSizeLimit)
+	}
+
+	return unpacked, nil
+}
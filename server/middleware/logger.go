@@ -0,0 +1,31 @@
+// Package middleware holds common middlewares for server.Handler.
+package middleware
+
+import (
+	"time"
+
+	"github.com/lazybark/go-tls-server/conn"
+	"github.com/lazybark/go-tls-server/server"
+)
+
+// Logger is implemented by log.Logger and most of logging libraries.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Logging writes a line about every message handled: connection, message length, time spent
+// and connection stats after the handler.
+func Logging(logger Logger) server.Middleware {
+	return func(next server.Handler) server.Handler {
+		return server.HandlerFunc(func(w server.ResponseWriter, m *conn.Message) {
+			start := time.Now()
+
+			next.ServeMessage(w, m)
+
+			c := m.Conn()
+			logger.Printf("[%s] %s: message of %d bytes handled in %v (sent %d, received %d, errors %d, last act %s)",
+				c.ID(), c.Address(), m.Length(), time.Since(start),
+				c.Sent(), c.Received(), c.Errors(), c.LastAct().Format(time.RFC3339))
+		})
+	}
+}
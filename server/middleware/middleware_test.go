@@ -0,0 +1,91 @@
+package middleware_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lazybark/go-helpers/mock"
+	"github.com/lazybark/go-tls-server/conn"
+	"github.com/lazybark/go-tls-server/server"
+	"github.com/lazybark/go-tls-server/server/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testWriter struct {
+	c       *conn.Connection
+	written []string
+}
+
+func (w *testWriter) Write(b []byte) (int, error) {
+	w.written = append(w.written, string(b))
+
+	return len(b), nil
+}
+
+func (w *testWriter) WriteString(s string) (int, error) { return w.Write([]byte(s)) }
+
+func (w *testWriter) Conn() *conn.Connection { return w.c }
+
+type testLogger struct {
+	lines int
+}
+
+func (l *testLogger) Printf(format string, v ...interface{}) { l.lines++ }
+
+func TestMiddlewareChain(t *testing.T) {
+	tlsConn := &mock.MockTLSConnection{}
+	cn, err := conn.NewConnection(tlsConn.RemoteAddr(), tlsConn, '\n')
+	require.NoError(t, err)
+
+	var (
+		got      []string
+		timed    int
+		panicked interface{}
+	)
+
+	logger := &testLogger{}
+	handler := server.Chain(
+		server.HandlerFunc(func(w server.ResponseWriter, m *conn.Message) {
+			if string(m.Bytes()) == "panic" {
+				panic("handler failed")
+			}
+
+			got = append(got, string(m.Bytes()))
+		}),
+		middleware.Logging(logger),
+		middleware.Timing(func(m *conn.Message, d time.Duration) { timed++ }),
+		middleware.Recover(func(w server.ResponseWriter, m *conn.Message, recovered interface{}) { panicked = recovered }),
+		middleware.Auth(func(m *conn.Message) error {
+			if bytes.HasPrefix(m.Bytes(), []byte("bad")) {
+				return errors.New("denied")
+			}
+
+			return nil
+		}, []byte("denied")),
+		middleware.Decompress(100),
+	)
+
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	_, err = zw.Write([]byte("unpacked"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	w := &testWriter{c: cn}
+	for _, b := range [][]byte{[]byte("plain"), []byte("bad one"), compressed.Bytes(), []byte("panic"), []byte(gzipBroken)} {
+		handler.ServeMessage(w, conn.NewMessage(cn, len(b), b))
+	}
+
+	assert.Equal(t, []string{"plain", "unpacked"}, got)
+	assert.Equal(t, []string{"denied"}, w.written)
+	assert.Equal(t, "handler failed", panicked)
+	assert.Equal(t, 5, timed) // Recover is inside of Timing, so panic does not break it.
+	assert.Equal(t, 5, logger.lines)
+	assert.Equal(t, 3, cn.Errors()) // Denied, panic and broken gzip.
+}
+
+const gzipBroken = "\x1f\x8bbroken"
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"github.com/lazybark/go-tls-server/conn"
+	"github.com/lazybark/go-tls-server/server"
+)
+
+// Recover recovers from handler panic and calls onPanic with recovered value.
+// It's useful to answer to remote or log panic in app-specific way. Without it server
+// still recovers the panic and sends it into error channel.
+//
+// Panic is counted in connection errors.
+func Recover(onPanic func(w server.ResponseWriter, m *conn.Message, recovered interface{})) server.Middleware {
+	return func(next server.Handler) server.Handler {
+		return server.HandlerFunc(func(w server.ResponseWriter, m *conn.Message) {
+			defer func() {
+				if r := recover(); r != nil {
+					m.Conn().AddErrors(1)
+					onPanic(w, m, r)
+				}
+			}()
+
+			next.ServeMessage(w, m)
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/lazybark/go-tls-server/conn"
+	"github.com/lazybark/go-tls-server/server"
+)
+
+// Timing calls observe with time spent by handler on every message.
+func Timing(observe func(m *conn.Message, d time.Duration)) server.Middleware {
+	return func(next server.Handler) server.Handler {
+		return server.HandlerFunc(func(w server.ResponseWriter, m *conn.Message) {
+			start := time.Now()
+
+			next.ServeMessage(w, m)
+
+			observe(m, time.Since(start))
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"sync"
 	"time"
 
@@ -15,9 +16,24 @@ func GetEmptyTestServer() *Server {
 	server.serverDoneChan = make(chan bool)
 	server.connChan = make(chan *conn.Connection)
 	server.connPool = make(map[string]*conn.Connection)
+	server.groups = make(map[string]map[string]*conn.Connection)
 	server.stat = make(map[string]Stat)
 	server.statOverall = new(Stat)
 	server.connPoolMutex = sync.RWMutex{}
+	server.mu = new(sync.Mutex)
+	server.parentCtx = context.Background()
+	server.ctx, server.cancel = context.WithCancel(server.parentCtx)
+	server.closing = make(chan struct{})
+	// Test server acts as if it's listening, so it can be stopped.
+	server.state = StateListening
+	server.isActive = true
+	server.sConfig = &Config{
+		MessageTerminator: '\n',
+		Framer:            conn.NewTerminatorFramer('\n'),
+		BufferSize:        128, //nolint:gomnd // It's OK
+		ErrorPrefix:       "TLS_SERVER",
+	}
+	server.errorPrefix = server.sConfig.ErrorPrefix
 
 	return server
 }
@@ -3,16 +3,27 @@ package server
 import (
 	"fmt"
 	"time"
+
+	"github.com/lazybark/go-tls-server/conn"
 )
 
 // adminRoutine controls server behaviour: drops closed connections,
 // closes inactive ones and stops the server in case s.ServerDoneChan.
-func (s *Server) adminRoutine() { //nolint:cyclop,gocognit // in TODOs
+// It returns once closing is closed, which means server run is stopped.
+func (s *Server) adminRoutine(closing chan struct{}) { //nolint:cyclop,gocognit // in TODOs
 	for {
 		select {
 		// Once per hour clean up old & close inactive connections.
 		case <-time.After(time.Hour):
+			s.connPoolMutex.RLock()
+			connections := make([]*conn.Connection, 0, len(s.connPool))
+
 			for _, connection := range s.connPool {
+				connections = append(connections, connection)
+			}
+			s.connPoolMutex.RUnlock()
+
+			for _, connection := range connections {
 				// If conn is closed and time now is already after the moment it should be deleted permanently.
 				if connection.Closed() && !time.Now().
 					Before(connection.ClosedAt().
@@ -26,26 +37,21 @@ func (s *Server) adminRoutine() { //nolint:cyclop,gocognit // in TODOs
 				if !connection.Closed() && s.sConfig.KeepInactiveConnections > 0 &&
 					!time.Now().Before(connection.LastAct().Add(time.Minute*time.Duration(s.sConfig.KeepInactiveConnections))) {
 					err := s.CloseConnection(connection)
-					if err != nil && !s.sConfig.SuppressErrors {
-						s.errChan <- s.FormatError(fmt.Errorf("[Listen] error closing connection: %w", err))
+					if err != nil {
+						s.sendError(fmt.Errorf("[adminRoutine] error closing connection: %w", err))
 					}
 				}
 			}
 		// In case server needs to be stopped - close all connections.
 		case d := <-s.serverDoneChan:
 			if d {
-				err := s.listener.Close()
-				if err != nil && !s.sConfig.SuppressErrors {
-					s.errChan <- s.FormatError(fmt.Errorf("[Listen] error closing listener: %w", err))
-				}
-
-				for _, c := range s.connPool {
-					err := s.CloseConnection(c)
-					if err != nil && !s.sConfig.SuppressErrors {
-						s.errChan <- s.FormatError(fmt.Errorf("[adminRoutine] error closing connection %s -> %w", c.ID(), err))
-					}
+				err := s.Stop()
+				if err != nil {
+					s.sendError(fmt.Errorf("[adminRoutine] error stopping server: %w", err))
 				}
 			}
+		case <-closing:
+			return
 		}
 	}
 }
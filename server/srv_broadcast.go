@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lazybark/go-tls-server/conn"
+)
+
+// BroadcastError holds errors of sending one message to several connections, by connection ID.
+type BroadcastError struct {
+	Errors map[string]error
+}
+
+func (e *BroadcastError) Error() string {
+	ids := make([]string, 0, len(e.Errors))
+	for id := range e.Errors {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	messages := make([]string, 0, len(ids))
+	for _, id := range ids {
+		messages = append(messages, fmt.Sprintf("%s: %v", id, e.Errors[id]))
+	}
+
+	return fmt.Sprintf("failed to send to %d connection(s): %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+// Broadcast sends b to every open connection in pool. See BroadcastFunc.
+func (s *Server) Broadcast(b []byte) (int, error) {
+	return s.BroadcastFunc(func(*conn.Connection) bool { return true }, b)
+}
+
+// BroadcastFunc sends b to every open connection in pool for which filter returns true.
+// Connections are written concurrently, so a slow one does not delay others. If BroadcastTimeout is set,
+// writes that didn't finish in time are stopped and reported with ErrSendTimeout.
+//
+// It returns number of connections b was sent to and *BroadcastError in case some of them failed.
+// Sent bytes and errors are added to Stat.
+func (s *Server) BroadcastFunc(filter func(c *conn.Connection) bool, b []byte) (int, error) {
+	var connections []*conn.Connection
+
+	s.connPoolMutex.RLock()
+	for _, c := range s.connPool {
+		if !c.Closed() && filter(c) {
+			connections = append(connections, c)
+		}
+	}
+	s.connPoolMutex.RUnlock()
+
+	return s.broadcast(connections, b, nil)
+}
+
+// SendTo sends b to connections with specified IDs. Closed and unknown connections are skipped
+// and reported in *BroadcastError. See BroadcastFunc.
+func (s *Server) SendTo(ids []string, b []byte) (int, error) {
+	var connections []*conn.Connection
+
+	errs := make(map[string]error)
+
+	s.connPoolMutex.RLock()
+	for _, id := range ids {
+		c, ok := s.connPool[id]
+
+		switch {
+		case !ok:
+			errs[id] = ErrConnectionNotFound
+		case c.Closed():
+			errs[id] = conn.ErrConnectionClosed
+		default:
+			connections = append(connections, c)
+		}
+	}
+	s.connPoolMutex.RUnlock()
+
+	return s.broadcast(connections, b, errs)
+}
+
+// broadcast calls to sendMany with write deadline set by BroadcastTimeout.
+func (s *Server) broadcast(connections []*conn.Connection, b []byte, errs map[string]error) (int, error) {
+	var (
+		ctx    = context.Background()
+		cancel context.CancelFunc
+	)
+
+	if s.sConfig.BroadcastTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, s.sConfig.BroadcastTimeout)
+		defer cancel()
+	}
+
+	return s.sendMany(ctx, connections, b, errs)
+}
+
+// sendMany writes b into connections concurrently and collects errors into errs.
+// Writes are stopped once ctx is done, connections that were not written by then are reported with ErrSendTimeout.
+func (s *Server) sendMany(ctx context.Context, connections []*conn.Connection, b []byte, errs map[string]error) (int, error) {
+	if errs == nil {
+		errs = make(map[string]error)
+	}
+
+	var (
+		sent int
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+	)
+
+	// Connections that were not written yet, in case timeout happens.
+	waiting := make(map[string]bool, len(connections))
+
+	for _, c := range connections {
+		waiting[c.ID()] = true
+	}
+
+	for _, c := range connections {
+		wg.Add(1)
+
+		go func(c *conn.Connection) {
+			defer wg.Done()
+
+			err := s.sendContext(ctx, c, b)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			delete(waiting, c.ID())
+
+			var canceled *conn.CanceledError
+			if errors.As(err, &canceled) {
+				err = ErrSendTimeout
+			}
+
+			if err != nil {
+				errs[c.ID()] = err
+
+				return
+			}
+
+			sent++
+		}(c)
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	for id := range waiting {
+		errs[id] = ErrSendTimeout
+	}
+
+	if len(errs) > 0 {
+		// Copy, because routines that were stopped by ctx may still write into errs.
+		result := &BroadcastError{Errors: make(map[string]error, len(errs))}
+		for id, err := range errs {
+			result.Errors[id] = err
+		}
+
+		return sent, result
+	}
+
+	return sent, nil
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/lazybark/go-tls-server/conn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroadcast(t *testing.T) {
+	srv := GetEmptyTestServer()
+	srv.sConfig.BroadcastTimeout = time.Millisecond * 200
+
+	newPipeConnection := func() (*conn.Connection, net.Conn) {
+		local, remote := net.Pipe()
+
+		c, err := conn.NewConnection(remote.RemoteAddr(), remote, '\n')
+		require.NoError(t, err)
+
+		srv.addToPool(c)
+
+		return c, local
+	}
+
+	fast, fastPeer := newPipeConnection()
+	defer fastPeer.Close()
+
+	// Nobody reads from slow connection, so writing blocks.
+	slow, slowPeer := newPipeConnection()
+	defer slowPeer.Close()
+
+	closed, closedPeer := newPipeConnection()
+	defer closedPeer.Close()
+	require.NoError(t, closed.Close())
+
+	received := make(chan string, 1)
+
+	go func() {
+		b := make([]byte, 128)
+		n, _ := fastPeer.Read(b)
+		received <- string(b[:n])
+	}()
+
+	sent, err := srv.Broadcast([]byte("hello"))
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, "hello\n", <-received)
+
+	var broadcastErr *BroadcastError
+	require.True(t, errors.As(err, &broadcastErr))
+	assert.Len(t, broadcastErr.Errors, 1)
+	assert.True(t, errors.Is(broadcastErr.Errors[slow.ID()], ErrSendTimeout))
+
+	// Timed out write is stopped, so it doesn't get ahead of the next one.
+	assert.Eventually(t, func() bool { return slow.Errors() == 1 }, time.Second, time.Millisecond*10)
+
+	slowReceived := make(chan string, 1)
+
+	go func() {
+		b := make([]byte, 128)
+		n, _ := slowPeer.Read(b)
+		slowReceived <- string(b[:n])
+	}()
+
+	require.NoError(t, srv.SendString(slow, "next"))
+	assert.Equal(t, "next\n", <-slowReceived)
+
+	// Unknown and closed connections are reported by SendTo.
+	go func() {
+		b := make([]byte, 128)
+		n, _ := fastPeer.Read(b)
+		received <- string(b[:n])
+	}()
+
+	sent, err = srv.SendTo([]string{fast.ID(), closed.ID(), "unknown"}, []byte("hi"))
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, "hi\n", <-received)
+	require.True(t, errors.As(err, &broadcastErr))
+	assert.True(t, errors.Is(broadcastErr.Errors[closed.ID()], conn.ErrConnectionClosed))
+	assert.True(t, errors.Is(broadcastErr.Errors["unknown"], ErrConnectionNotFound))
+
+	// Filter leaves only fast connection.
+	go func() {
+		b := make([]byte, 128)
+		n, _ := fastPeer.Read(b)
+		received <- string(b[:n])
+	}()
+
+	sent, err = srv.BroadcastFunc(func(c *conn.Connection) bool { return c.ID() == fast.ID() }, []byte("only"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, "only\n", <-received)
+
+	sentBytes, _, _, err := srv.StatsOverall()
+	require.NoError(t, err)
+	assert.Equal(t, len("hello\nnext\nhi\nonly\n"), sentBytes)
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ReloadCertificates loads certificate and key from files the server was created with (see New).
+// New handshakes use new certificate, while existing connections keep working.
+// In case of error old certificate is kept. Server that was not created from files returns ErrNoCertificateFiles.
+func (s *Server) ReloadCertificates() error {
+	err := s.loadCertificate()
+	if err != nil {
+		return s.FormatError(fmt.Errorf("[ReloadCertificates] %w", err))
+	}
+
+	return nil
+}
+
+// loadCertificate loads key pair from certFile and keyFile and makes it current certificate.
+func (s *Server) loadCertificate() error {
+	if s.certFile == "" {
+		return ErrNoCertificateFiles
+	}
+
+	certificate, err := tls.LoadX509KeyPair(s.certFile, s.keyFile)
+	if err != nil {
+		return fmt.Errorf("error getting key pair: %w", err)
+	}
+
+	s.certMutex.Lock()
+	s.certificate = &certificate
+	s.certMutex.Unlock()
+
+	return nil
+}
+
+// getCertificate returns certificate for server name client requested (see NewWithSNI)
+// or current default certificate.
+func (s *Server) getCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	s.certMutex.RLock()
+	defer s.certMutex.RUnlock()
+
+	name := strings.ToLower(hello.ServerName)
+
+	if certificate, ok := s.hostCertificates[name]; ok {
+		return certificate, nil
+	}
+
+	// Wildcard certificate covers exactly one label: "*.example.com" for "a.example.com".
+	if i := strings.IndexByte(name, '.'); i > 0 {
+		if certificate, ok := s.hostCertificates["*"+name[i:]]; ok {
+			return certificate, nil
+		}
+	}
+
+	if s.certificate == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownServerName, hello.ServerName)
+	}
+
+	return s.certificate, nil
+}
+
+// watchCertificates reloads certificates once certificate or key file is changed.
+// It returns once closing is closed, which means server run is stopped.
+func (s *Server) watchCertificates(interval time.Duration, closing chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := s.certFilesVersion()
+
+	for {
+		select {
+		case <-ticker.C:
+			current := s.certFilesVersion()
+			if current == last {
+				continue
+			}
+
+			last = current
+
+			err := s.ReloadCertificates()
+			if err != nil {
+				s.sendError(err)
+			}
+		case <-closing:
+			return
+		}
+	}
+}
+
+// certFilesVersion returns string that changes once certificate or key file is modified.
+func (s *Server) certFilesVersion() string {
+	version := ""
+
+	for _, file := range []string{s.certFile, s.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			version += "missing;"
+
+			continue
+		}
+
+		version += fmt.Sprintf("%d:%d;", info.ModTime().UnixNano(), info.Size())
+	}
+
+	return version
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloadCertificates(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+
+	writeCertificate := func() string {
+		certificate, certPEM, keyPEM := newTestCertificate(t, "localhost", "127.0.0.1")
+		require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+		require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+
+		return string(certificate.Certificate[0])
+	}
+
+	first := writeCertificate()
+
+	srv, err := New(context.Background(), "localhost", certFile, keyFile,
+		&Config{CertReloadInterval: time.Millisecond * 20})
+	require.NoError(t, err)
+	require.NoError(t, srv.ListenAddr("127.0.0.1:0"))
+
+	addr := srv.Addrs()[0].String()
+
+	peerCertificate := func() string {
+		client, _ := dialTestServer(t, srv, addr, nil)
+		defer client.Close()
+
+		return string(client.ConnectionState().PeerCertificates[0].Raw)
+	}
+
+	oldClient, oldConnection := dialTestServer(t, srv, addr, nil)
+	defer oldClient.Close()
+
+	assert.Equal(t, first, string(oldClient.ConnectionState().PeerCertificates[0].Raw))
+
+	// New handshakes use new certificate.
+	second := writeCertificate()
+	assert.Eventually(t, func() bool { return peerCertificate() == second }, time.Second, time.Millisecond*20)
+
+	// Existing connection keeps working.
+	_, err = oldClient.Write([]byte("still here\n"))
+	require.NoError(t, err)
+
+	message, err := oldConnection.GetMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "still here", string(message.Bytes()))
+
+	// Broken files are reported and old certificate is kept.
+	require.NoError(t, os.WriteFile(certFile, []byte("broken"), 0o600))
+	assert.Error(t, srv.Error())
+	assert.Equal(t, second, peerCertificate())
+
+	assert.Error(t, srv.ReloadCertificates())
+	assert.Equal(t, second, peerCertificate())
+
+	require.NoError(t, srv.Stop())
+}
+
+func TestReloadCertificatesWithoutFiles(t *testing.T) {
+	_, certPEM, keyPEM := newTestCertificate(t, "localhost")
+
+	srv, err := NewFromPEM(context.Background(), "localhost", certPEM, keyPEM, nil)
+	require.NoError(t, err)
+
+	assert.True(t, errors.Is(srv.ReloadCertificates(), ErrNoCertificateFiles))
+}
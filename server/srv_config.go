@@ -1,5 +1,12 @@
 package server
 
+import (
+	"crypto/x509"
+	"time"
+
+	"github.com/lazybark/go-tls-server/conn"
+)
+
 type Config struct {
 	// SuppressErrors prevents server from sending errors into ErrChan.
 	// Does not include fatal errors during startup.
@@ -16,6 +23,26 @@ type Config struct {
 	// Works for both incoming and outgoing messages.
 	MessageTerminator byte
 
+	// MessageDelimiter sets multi-byte sequence that marks end of the message in stream, e.g. "\r\n".
+	// Overrides MessageTerminator if not empty.
+	MessageDelimiter []byte
+
+	// MessageEscaping turns on escaping of MessageTerminator (or MessageDelimiter) inside messages,
+	// so payload may contain any bytes without splitting one message into two.
+	// Both peers must have the same value.
+	// Ignored if Framer is set.
+	MessageEscaping bool
+
+	// Framer sets the way messages are separated in the stream. Both peers must use the same framing.
+	// Use conn.NewLengthPrefixFramer to send arbitrary binary payloads.
+	//
+	// Default: conn.TerminatorFramer with MessageTerminator / MessageDelimiter and MessageEscaping.
+	Framer conn.Framer
+
+	// PlainUnixSockets turns off TLS on Unix domain socket listeners ("unix:///path.sock" addresses),
+	// so local peers talk to server in plain text. TCP listeners always use TLS.
+	PlainUnixSockets bool
+
 	// BufferSize regulates buffer length to read incoming message. Default value is 128.
 	BufferSize int
 
@@ -29,6 +56,52 @@ type Config struct {
 	// 0 means keep such connection forever.
 	KeepInactiveConnections int
 
+	// MaxHandlers limits number of message handlers running at the same time in Serve.
+	// 0 means no limit.
+	MaxHandlers int
+
+	// MaxConnectionHandlers limits number of message handlers running at the same time for one connection in Serve.
+	// Default value (in case 0) is 1, which means messages of a connection are handled one by one in order they came.
+	MaxConnectionHandlers int
+
+	// BroadcastTimeout limits time Broadcast, BroadcastFunc and SendTo wait for slow connections.
+	// It's applied as write deadline: writes that didn't finish in time are stopped and reported with ErrSendTimeout.
+	// Such connection may have got part of the message, so it should be closed.
+	// 0 means wait for all connections.
+	BroadcastTimeout time.Duration
+
+	// GoingAwayMessage is sent to every open connection by Shutdown, so remote can finish its work and disconnect.
+	// Empty means no message.
+	GoingAwayMessage []byte
+
+	// CertReloadInterval makes server check certificate and key files (see New) for changes with this interval
+	// and reload them. Failed reload is sent into error channel and old certificate is kept.
+	// 0 means files are not watched, but certificates still can be reloaded by ReloadCertificates.
+	CertReloadInterval time.Duration
+
+	// TLSPolicy sets TLS versions, cipher suites, curves, ALPN protocols and session tickets server allows.
+	// It overrides the same settings of TLS config passed to NewWithTLSConfig. See conn.ModernTLSPolicy
+	// and conn.CompatibleTLSPolicy for presets. Invalid policy makes constructors return error.
+	//
+	// Default: TLS 1.2 is the minimal version, other settings are defaults of crypto/tls.
+	TLSPolicy *conn.TLSPolicy
+
+	// HandshakeTimeout limits time of TLS handshake. Connection that didn't complete handshake in time is closed
+	// and never gets into connection pool. Default value (in case 0) is 10 seconds.
+	HandshakeTimeout time.Duration
+
+	// MaxHandshakes limits number of TLS handshakes in progress at the same time.
+	// Server doesn't accept new connections until one of handshakes is done. 0 means no limit.
+	MaxHandshakes int
+
+	// ClientCAs turns on mutual TLS: server requires client certificate and verifies it against this pool.
+	// Verified identity is available by Connection.PeerIdentity.
+	ClientCAs *x509.CertPool
+
+	// VerifyPeer is called after client certificate was verified (see ClientCAs). Returned error rejects handshake,
+	// so certain identities can be banned without extra auth protocol. Peer is nil if client presented no certificate.
+	VerifyPeer func(peer *conn.PeerIdentity) error
+
 	// ErrorPrefix is used as prefix to all errors to identify specific instance of server.
 	//
 	// Default: "TLS_SERVER"
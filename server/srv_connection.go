@@ -1,6 +1,8 @@
 package server
 
 import (
+	"context"
+
 	"github.com/lazybark/go-tls-server/conn"
 )
 
@@ -22,11 +24,13 @@ func (s *Server) addToPool(c *conn.Connection) {
 	s.connPoolMutex.Unlock()
 }
 
-// remFromPool removes connection pointer from pool, so it becomes unavailable to reach.
+// remFromPool removes connection pointer from pool and its groups, so it becomes unavailable to reach.
 func (s *Server) remFromPool(c *conn.Connection) {
 	s.connPoolMutex.Lock()
 	delete(s.connPool, c.ID())
 	s.connPoolMutex.Unlock()
+
+	s.leaveAll(c)
 }
 
 // SendByte calls to c.SendByte and adds sent bytes to Stat.
@@ -42,6 +46,19 @@ func (s *Server) SendByte(c *conn.Connection, b []byte) error {
 	return s.FormatError(err)
 }
 
+// sendContext calls to c.SendContext and adds sent bytes to Stat.
+func (s *Server) sendContext(ctx context.Context, c *conn.Connection, b []byte) error {
+	n, err := c.SendContext(ctx, b)
+
+	s.addSentBytes(n)
+
+	if err != nil {
+		s.addErrors(1)
+	}
+
+	return s.FormatError(err)
+}
+
 // SendString calls to c.SendString and adds sent bytes to Stat.
 func (s *Server) SendString(c *conn.Connection, str string) error {
 	n, err := c.SendString(str)
@@ -53,3 +70,32 @@ func (s *Server) SendString(c *conn.Connection, str string) error {
 
 	return s.FormatError(err)
 }
+
+// Request calls to c.Request and adds bytes sent by request to Stat. Reply is counted by reader
+// as every received message.
+func (s *Server) Request(ctx context.Context, c *conn.Connection, b []byte) (*conn.Message, error) {
+	reply, sent, err := c.RequestSent(ctx, b)
+
+	s.addSentBytes(sent)
+
+	if err != nil {
+		s.addErrors(1)
+
+		return nil, s.FormatError(err)
+	}
+
+	return reply, nil
+}
+
+// Reply calls to m.Reply and adds sent bytes to Stat.
+func (s *Server) Reply(m *conn.Message, b []byte) error {
+	n, err := m.Reply(b)
+
+	s.addSentBytes(n)
+
+	if err != nil {
+		s.addErrors(1)
+	}
+
+	return s.FormatError(err)
+}
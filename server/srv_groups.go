@@ -0,0 +1,105 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/lazybark/go-tls-server/conn"
+)
+
+// Join adds connection to group. Group is created on first join and dropped when last member leaves.
+// Connection leaves all groups automatically once it's closed or removed from pool.
+func (s *Server) Join(group string, c *conn.Connection) error {
+	// Checks are made under groupsMutex: reader leaves all groups after connection is closed and pool
+	// removes connection before it leaves, so connection that passed them can't be left behind.
+	s.groupsMutex.Lock()
+	defer s.groupsMutex.Unlock()
+
+	if c.Closed() {
+		return s.FormatError(fmt.Errorf("[Join] %w", conn.ErrConnectionClosed))
+	}
+
+	s.connPoolMutex.RLock()
+	_, ok := s.connPool[c.ID()]
+	s.connPoolMutex.RUnlock()
+
+	if !ok {
+		return s.FormatError(fmt.Errorf("[Join] %w", ErrConnectionNotFound))
+	}
+
+	if s.groups[group] == nil {
+		s.groups[group] = make(map[string]*conn.Connection)
+	}
+
+	s.groups[group][c.ID()] = c
+
+	return nil
+}
+
+// Leave removes connection from group.
+func (s *Server) Leave(group string, c *conn.Connection) {
+	s.groupsMutex.Lock()
+	defer s.groupsMutex.Unlock()
+
+	s.leave(group, c.ID())
+}
+
+// Members returns open connections of the group.
+func (s *Server) Members(group string) []*conn.Connection {
+	s.groupsMutex.RLock()
+	defer s.groupsMutex.RUnlock()
+
+	members := make([]*conn.Connection, 0, len(s.groups[group]))
+
+	for _, c := range s.groups[group] {
+		if !c.Closed() {
+			members = append(members, c)
+		}
+	}
+
+	return members
+}
+
+// Groups returns names of groups connection is in.
+func (s *Server) Groups(c *conn.Connection) []string {
+	s.groupsMutex.RLock()
+	defer s.groupsMutex.RUnlock()
+
+	var groups []string
+
+	for name, members := range s.groups {
+		if _, ok := members[c.ID()]; ok {
+			groups = append(groups, name)
+		}
+	}
+
+	return groups
+}
+
+// SendToGroup sends b to all open connections of the group. See BroadcastFunc.
+func (s *Server) SendToGroup(group string, b []byte) (int, error) {
+	return s.broadcast(s.Members(group), b, nil)
+}
+
+// leaveAll removes connection from all groups.
+func (s *Server) leaveAll(c *conn.Connection) {
+	s.groupsMutex.Lock()
+	defer s.groupsMutex.Unlock()
+
+	for name := range s.groups {
+		s.leave(name, c.ID())
+	}
+}
+
+// leave removes connection from group and drops empty group. Caller must hold groupsMutex.
+func (s *Server) leave(group string, id string) {
+	members, ok := s.groups[group]
+	if !ok {
+		return
+	}
+
+	delete(members, id)
+
+	if len(members) == 0 {
+		delete(s.groups, group)
+	}
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lazybark/go-helpers/mock"
+	"github.com/lazybark/go-tls-server/conn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroups(t *testing.T) {
+	srv := GetEmptyTestServer()
+
+	newConnection := func() (*conn.Connection, *mock.MockTLSConnection) {
+		tlsConn := &mock.MockTLSConnection{}
+
+		c, err := conn.NewConnection(tlsConn.RemoteAddr(), tlsConn, '\n')
+		require.NoError(t, err)
+
+		srv.addToPool(c)
+
+		return c, tlsConn
+	}
+
+	first, firstTLS := newConnection()
+	second, secondTLS := newConnection()
+	third, _ := newConnection()
+
+	require.NoError(t, srv.Join("room", first))
+	require.NoError(t, srv.Join("room", second))
+	require.NoError(t, srv.Join("other", second))
+	require.NoError(t, srv.Join("other", third))
+
+	assert.Len(t, srv.Members("room"), 2)
+	assert.ElementsMatch(t, []string{"room", "other"}, srv.Groups(second))
+
+	sent, err := srv.SendToGroup("room", []byte("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	assert.Equal(t, "hello\n", string(firstTLS.MWR.Bytes))
+	assert.Equal(t, "hello\n", string(secondTLS.MWR.Bytes))
+
+	// Closed connection is not a member, removed one leaves all groups.
+	require.NoError(t, first.Close())
+	assert.Len(t, srv.Members("room"), 1)
+
+	srv.remFromPool(second)
+	assert.Empty(t, srv.Members("room"))
+	assert.Empty(t, srv.Groups(second))
+	assert.Len(t, srv.Members("other"), 1)
+
+	srv.Leave("other", third)
+	assert.Empty(t, srv.Members("other"))
+
+	// Only open connections from pool can join.
+	assert.True(t, errors.Is(srv.Join("room", first), conn.ErrConnectionClosed))
+	assert.True(t, errors.Is(srv.Join("room", second), ErrConnectionNotFound))
+}
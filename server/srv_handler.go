@@ -0,0 +1,152 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/lazybark/go-tls-server/conn"
+	"github.com/lazybark/go-tls-server/handler"
+)
+
+// Handler responds to a message received by server. See handler.Handler.
+type Handler = handler.Handler
+
+// HandlerFunc allows to use ordinary functions as handlers.
+type HandlerFunc = handler.HandlerFunc
+
+// ResponseWriter is used by handler to answer to the message.
+type ResponseWriter = handler.ResponseWriter
+
+// responseWriter writes into message connection and adds sent bytes to server Stat.
+type responseWriter struct {
+	server  *Server
+	message *conn.Message
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	var (
+		n   int
+		err error
+	)
+
+	if w.message.IsRequest() {
+		n, err = w.message.Reply(b)
+	} else {
+		n, err = w.message.Conn().SendByte(b)
+	}
+
+	w.server.addSentBytes(n)
+
+	if err != nil {
+		w.server.addErrors(1)
+
+		return n, w.server.FormatError(err)
+	}
+
+	return n, nil
+}
+
+func (w *responseWriter) WriteString(s string) (int, error) { return w.Write([]byte(s)) }
+
+func (w *responseWriter) Conn() *conn.Connection { return w.message.Conn() }
+
+// Serve accepts new connections and calls handler for every message received. Server must be listening.
+// Messages of one connection are handled one by one in order they came, unless MaxConnectionHandlers > 1.
+// Panics in handler are recovered and sent into error channel. Handler is wrapped into middlewares added by Use.
+//
+// Serve blocks until server is stopped and then returns ErrServerClosed.
+func (s *Server) Serve(handler Handler) error {
+	var limit chan struct{}
+	if s.sConfig.MaxHandlers > 0 {
+		limit = make(chan struct{}, s.sConfig.MaxHandlers)
+	}
+
+	s.mu.Lock()
+	handler = Chain(handler, s.middlewares...)
+	s.mu.Unlock()
+
+	for {
+		connection, err := s.AcceptConnection()
+		if err != nil {
+			return err
+		}
+
+		go s.serveConnection(connection, handler, limit)
+	}
+}
+
+// serveConnection reads messages from connection until it's closed and passes them to handler.
+// limit is shared by all connections to cap number of running handlers.
+func (s *Server) serveConnection(connection *conn.Connection, handler Handler, limit chan struct{}) {
+	perConnection := s.sConfig.MaxConnectionHandlers
+	if perConnection < 1 {
+		perConnection = 1
+	}
+
+	connLimit := make(chan struct{}, perConnection)
+
+	// Messages are released after handler is done, not on delivery.
+	connection.SetServed()
+
+	var wg sync.WaitGroup
+
+	for {
+		message, err := connection.GetMessage()
+		if err != nil {
+			break
+		}
+
+		connLimit <- struct{}{}
+
+		if limit != nil {
+			limit <- struct{}{}
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer func() {
+				if limit != nil {
+					<-limit
+				}
+
+				<-connLimit
+
+				// Message was counted as in flight by the reader.
+				connection.AddInFlight(-1)
+				wg.Done()
+			}()
+
+			s.handle(handler, message)
+		}()
+	}
+
+	wg.Wait()
+}
+
+// handle calls handler and recovers from its panic.
+func (s *Server) handle(handler Handler, message *conn.Message) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.addErrors(1)
+			s.sendError(fmt.Errorf("[Serve] %w on %s: %v", ErrHandlerPanic, message.Conn().ID(), r))
+		}
+	}()
+
+	handler.ServeMessage(&responseWriter{server: s, message: message}, message)
+}
+
+// Middleware wraps handler to run common logic before and/or after it.
+type Middleware = handler.Middleware
+
+// Chain wraps handler into middlewares. First middleware is the outermost one,
+// so it runs first for every message.
+func Chain(h Handler, middlewares ...Middleware) Handler { return handler.Chain(h, middlewares...) }
+
+// Use adds middlewares that wrap handler passed to Serve. It should be called before Serve.
+func (s *Server) Use(middlewares ...Middleware) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.middlewares = append(s.middlewares, middlewares...)
+}
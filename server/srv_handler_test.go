@@ -0,0 +1,107 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/lazybark/go-tls-server/conn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeHandler(t *testing.T) {
+	srv := GetEmptyTestServer()
+
+	go func() {
+		_ = srv.Serve(HandlerFunc(func(w ResponseWriter, m *conn.Message) {
+			if string(m.Bytes()) == "panic" {
+				panic("handler failed")
+			}
+
+			_, _ = w.WriteString("re: " + string(m.Bytes()))
+		}))
+	}()
+
+	local, remote := net.Pipe()
+	defer local.Close()
+
+	connection, err := conn.NewConnection(remote.RemoteAddr(), remote, '\n')
+	require.NoError(t, err)
+
+	srv.addToPool(connection)
+	go srv.receive(connection)
+	srv.connChan <- connection
+
+	client, err := conn.NewConnection(local.RemoteAddr(), local, '\n')
+	require.NoError(t, err)
+
+	_, err = client.SendString("ping")
+	require.NoError(t, err)
+
+	read, _, err := client.ReadMessage(128, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "re: ping", string(read))
+
+	// Panic is recovered and reported, connection keeps working.
+	_, err = client.SendString("panic")
+	require.NoError(t, err)
+	assert.True(t, errors.Is(srv.Error(), ErrHandlerPanic))
+
+	_, err = client.SendString("pong")
+	require.NoError(t, err)
+
+	read, _, err = client.ReadMessage(128, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "re: pong", string(read))
+
+	// Bytes are counted right after they are written.
+	assert.Eventually(t, func() bool {
+		sent, received, errs, _ := srv.StatsOverall()
+
+		return sent == len("re: ping\nre: pong\n") && received == len("ping\npanic\npong\n") && errs == 1
+	}, time.Second, time.Millisecond*10)
+}
+
+func TestDeliverReleasesByOwner(t *testing.T) {
+	srv := GetEmptyTestServer()
+
+	newPipeConnection := func() *conn.Connection {
+		_, remote := net.Pipe()
+
+		connection, err := conn.NewConnection(remote.RemoteAddr(), remote, '\n')
+		require.NoError(t, err)
+
+		return connection
+	}
+
+	// Message taken by GetMessage is not in flight anymore.
+	taken := newPipeConnection()
+
+	go func() { _, _ = taken.GetMessage() }()
+
+	assert.True(t, srv.deliver(taken, conn.NewMessage(taken, 4, []byte("ping"))))
+	assert.True(t, taken.Idle())
+
+	// Message of served connection is in flight until handler returns, even if Serve returned already.
+	served := newPipeConnection()
+	release := make(chan struct{})
+	handled := make(chan struct{})
+
+	go func() {
+		srv.serveConnection(served, HandlerFunc(func(w ResponseWriter, m *conn.Message) { <-release }), nil)
+		close(handled)
+	}()
+
+	assert.Eventually(t, served.Served, time.Second, time.Millisecond*10)
+	assert.True(t, srv.deliver(served, conn.NewMessage(served, 4, []byte("ping"))))
+	assert.Equal(t, 1, served.InFlight())
+
+	close(release)
+	require.NoError(t, served.Abort())
+	served.CloseMessageChan()
+	<-handled
+
+	assert.Equal(t, 0, served.InFlight())
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandshakeTimeout(t *testing.T) {
+	srv := newTestTLSServer(t)
+	srv.sConfig.HandshakeTimeout = time.Millisecond * 100
+
+	require.NoError(t, srv.ListenAddr("127.0.0.1:0"))
+
+	// Peer connects, but never starts handshake.
+	silent, err := net.Dial("tcp", srv.Addrs()[0].String())
+	require.NoError(t, err)
+
+	defer silent.Close()
+
+	assert.Error(t, srv.Error())
+	assert.Equal(t, 1, srv.StatsHandshakeErrors())
+
+	// Handshake errors are not read/write errors and connection never gets into pool.
+	_, _, errs, err := srv.StatsOverall()
+	require.NoError(t, err)
+	assert.Equal(t, 0, errs)
+
+	count, err := srv.StatsConnections()
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	require.NoError(t, srv.Stop())
+}
+
+func TestMaxHandshakes(t *testing.T) {
+	srv := newTestTLSServer(t)
+	srv.sConfig.HandshakeTimeout = time.Millisecond * 300
+	srv.handshakes = make(chan struct{}, 1)
+
+	require.NoError(t, srv.ListenAddr("127.0.0.1:0"))
+
+	go func() {
+		for {
+			if srv.Error() == nil {
+				return
+			}
+		}
+	}()
+
+	// Silent peer takes the only handshake slot until timeout.
+	silent, err := net.Dial("tcp", srv.Addrs()[0].String())
+	require.NoError(t, err)
+
+	defer silent.Close()
+
+	assert.Eventually(t, func() bool { return len(srv.handshakes) == 1 }, time.Second, time.Millisecond)
+
+	started := time.Now()
+
+	client, _ := dialTestServer(t, srv, srv.Addrs()[0].String(), nil)
+	defer client.Close()
+
+	assert.GreaterOrEqual(t, time.Since(started), time.Millisecond*200)
+	assert.Eventually(t, func() bool { return srv.StatsHandshakeErrors() == 1 }, time.Second, time.Millisecond)
+
+	require.NoError(t, srv.Stop())
+}
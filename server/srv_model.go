@@ -11,10 +11,22 @@ import (
 
 	"github.com/lazybark/go-helpers/semver"
 	"github.com/lazybark/go-tls-server/conn"
+	"github.com/lazybark/go-tls-server/handler"
 )
 
 var (
 	ErrServerClosed = errors.New("server is closed")
+	ErrHandlerPanic = handler.ErrHandlerPanic
+
+	ErrInvalidTransition  = errors.New("invalid server state transition")
+	ErrConnectionNotFound = errors.New("connection not found")
+	ErrSendTimeout        = errors.New("send timeout")
+	ErrNoListeners        = errors.New("no listeners")
+	ErrSocketInUse        = errors.New("socket is in use")
+	ErrNotSocket          = errors.New("file is not a socket")
+	ErrNoCertificate      = errors.New("no server certificate in TLS config")
+	ErrNoCertificateFiles = errors.New("server certificate was not loaded from files")
+	ErrUnknownServerName  = errors.New("no certificate for server name")
 )
 
 type Server struct {
@@ -23,6 +35,13 @@ type Server struct {
 	isActive bool
 	mu       *sync.Mutex
 
+	// state is the lifecycle state of server.
+	state State
+
+	// closing is closed once Stop or Shutdown is called. Routines that send into server channels
+	// select on it, so they never block on a stopped server. It's renewed on every run.
+	closing chan struct{}
+
 	timeStart time.Time
 
 	// host = hostname of the server.
@@ -37,12 +56,34 @@ type Server struct {
 	// connPoolMutex controls connPool.
 	connPoolMutex sync.RWMutex
 
-	// listener is the interface that listens for new connections.
-	listener net.Listener
+	// groups holds connections by group name and connection ID.
+	groups map[string]map[string]*conn.Connection
+
+	// groupsMutex controls groups.
+	groupsMutex sync.RWMutex
+
+	// listeners are the interfaces that listen for new connections in current run.
+	listeners []net.Listener
+
+	// handshakes holds a token for every TLS handshake in progress, nil means no limit (see Config.MaxHandshakes).
+	handshakes chan struct{}
 
 	// tlsConfig points to tls listener config.
 	tlsConfig *tls.Config
 
+	// certFile and keyFile are paths of certificate and key the server was created with (see New).
+	certFile string
+	keyFile  string
+
+	// certificate is the certificate loaded from certFile and keyFile or the default one of NewWithSNI.
+	certificate *tls.Certificate
+
+	// hostCertificates holds certificates by server name (see NewWithSNI).
+	hostCertificates map[string]*tls.Certificate
+
+	// certMutex controls certificate and hostCertificates.
+	certMutex sync.RWMutex
+
 	// sConfig points to server config.
 	sConfig *Config
 
@@ -62,10 +103,16 @@ type Server struct {
 	// statOverall keeps stats for all working time.
 	statOverall *Stat
 
-	// ctx is the server context.
+	// ctx is the context of current server run.
 	ctx    context.Context //nolint:containedctx // In TODOs
 	cancel context.CancelFunc
 
+	// parentCtx is the context server was created with. Context of every run is derived from it.
+	parentCtx context.Context //nolint:containedctx // In TODOs
+
+	// middlewares wrap handler passed to Serve.
+	middlewares []Middleware
+
 	// errorPrefix is used as prefix to all errors to identify specific instance of server.
 	//
 	// Default: "TLS_SERVER".
@@ -79,14 +126,14 @@ func (s *Server) Version() semver.Ver { return s.ver }
 func (s *Server) VersionString() string { return s.ver.String() }
 
 // Error returns exactly one of server errors. Keep it running in separate routine to receive
-// all errors as they appear.
+// all errors as they appear. It returns nil once server is stopped.
 func (s *Server) Error() error {
-	err, ok := <-s.errChan
-	if !ok {
+	select {
+	case err := <-s.errChan:
+		return err
+	case <-s.closingChan():
 		return nil
 	}
-
-	return err
 }
 
 // Next returns true if server is active and able to receive new connections.
@@ -97,12 +144,12 @@ func (s *Server) Next() bool {
 // AcceptConnection returns new connection or error. Code will be locked until new connection appears
 // or server is stopped. The only possible error is ErrServerClosed.
 func (s *Server) AcceptConnection() (*conn.Connection, error) {
-	connection, ok := <-s.connChan
-	if !ok {
+	select {
+	case connection := <-s.connChan:
+		return connection, nil
+	case <-s.closingChan():
 		return nil, ErrServerClosed
 	}
-
-	return connection, nil
 }
 
 // FormatError adds server's error prefix to err.
@@ -114,6 +161,19 @@ func (s *Server) FormatError(err error) error {
 	return fmt.Errorf("%s: %w", s.errorPrefix, err)
 }
 
+// sendError sends formatted err into error channel unless errors are suppressed.
+// Errors that happen after server was stopped are dropped.
+func (s *Server) sendError(err error) {
+	if s.sConfig.SuppressErrors {
+		return
+	}
+
+	select {
+	case s.errChan <- s.FormatError(err):
+	case <-s.closingChan():
+	}
+}
+
 // SetActive sets server status to the value of active.
 func (s *Server) SetActive(active bool) {
 	s.mu.Lock()
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"testing"
+
+	"github.com/lazybark/go-tls-server/conn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMutualTLS(t *testing.T) {
+	serverCertificate, _, _ := newTestCertificate(t, "localhost")
+	trusted, trustedPEM, _ := newTestCertificate(t, "client-1", "client-1.internal")
+	banned, bannedPEM, _ := newTestCertificate(t, "banned")
+	unknown, _, _ := newTestCertificate(t, "unknown")
+
+	pool := x509.NewCertPool()
+	require.True(t, pool.AppendCertsFromPEM(trustedPEM))
+	require.True(t, pool.AppendCertsFromPEM(bannedPEM))
+
+	srv, err := NewWithCertificates(context.Background(), "localhost", &Config{
+		ClientCAs: pool,
+		VerifyPeer: func(peer *conn.PeerIdentity) error {
+			if peer.CommonName == "banned" {
+				return errors.New("banned")
+			}
+
+			return nil
+		},
+	}, serverCertificate)
+	require.NoError(t, err)
+	require.NoError(t, srv.ListenAddr("127.0.0.1:0"))
+
+	addr := srv.Addrs()[0].String()
+
+	// Errors of failed handshakes are read by server routines.
+	go func() {
+		for {
+			if srv.Error() == nil {
+				return
+			}
+		}
+	}()
+
+	config := func(certificates ...tls.Certificate) *tls.Config {
+		return &tls.Config{Certificates: certificates, InsecureSkipVerify: true} //nolint:gosec // test
+	}
+
+	client, connection := dialTestServer(t, srv, addr, config(trusted))
+	defer client.Close()
+
+	// Message is read after handshake is complete on server side.
+	_, err = client.Write([]byte("hi\n"))
+	require.NoError(t, err)
+
+	_, err = connection.GetMessage()
+	require.NoError(t, err)
+
+	peer := connection.PeerIdentity()
+	require.NotNil(t, peer)
+	assert.Equal(t, "client-1", peer.CommonName)
+	assert.Equal(t, []string{"client-1", "client-1.internal"}, peer.DNSNames)
+	require.Len(t, peer.Chain, 1)
+	assert.Equal(t, trusted.Certificate[0], peer.Chain[0].Raw)
+
+	// Client is rejected if it has no certificate, certificate is unknown or banned by VerifyPeer.
+	for _, certificates := range [][]tls.Certificate{nil, {unknown}, {banned}} {
+		go func() { _, _ = srv.AcceptConnection() }()
+
+		rejected, err := tls.Dial("tcp", addr, config(certificates...))
+		if err == nil {
+			// With TLS 1.3 client learns about rejection on first read.
+			_, err = rejected.Read(make([]byte, 1))
+			rejected.Close()
+		}
+
+		assert.Error(t, err)
+	}
+
+	require.NoError(t, srv.Stop())
+}
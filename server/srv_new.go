@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,7 +13,175 @@ import (
 )
 
 // New initializes server instance and makes it completely ready to listen for connections.
-func New(ctx context.Context, host string, cert string, key string, conf *Config) (*Server, error) { //nolint: funlen // false alarm
+// It reads certificate and key from files, use NewWithTLSConfig, NewWithCertificates, NewFromPEM
+// or NewWithGetCertificate for certificates that live in memory.
+//
+// Files can be reloaded later by ReloadCertificates or automatically (see Config.CertReloadInterval).
+func New(ctx context.Context, host string, cert string, key string, conf *Config) (*Server, error) {
+	server := newServer(ctx, host, conf)
+	server.certFile = cert
+	server.keyFile = key
+
+	err := server.loadCertificate()
+	if err != nil {
+		return nil, server.FormatError(err)
+	}
+
+	// Certificate is taken on every handshake, so it can be reloaded.
+	tlsConfig := defaultTLSConfig()
+	tlsConfig.GetCertificate = server.getCertificate
+
+	err = server.setTLSConfig(tlsConfig)
+	if err != nil {
+		return nil, server.FormatError(err)
+	}
+
+	return server, nil
+}
+
+// NewWithTLSConfig initializes server that uses copy of tlsConfig. Config must provide
+// server certificate by Certificates, GetCertificate or GetConfigForClient.
+// TLS 1.2 is the minimal version unless tlsConfig or Config.TLSPolicy sets MinVersion.
+func NewWithTLSConfig(ctx context.Context, host string, tlsConfig *tls.Config, conf *Config) (*Server, error) {
+	server := newServer(ctx, host, conf)
+
+	err := server.setTLSConfig(tlsConfig)
+	if err != nil {
+		return nil, server.FormatError(err)
+	}
+
+	return server, nil
+}
+
+// NewWithCertificates initializes server that uses certificates.
+func NewWithCertificates(ctx context.Context, host string, conf *Config, certificates ...tls.Certificate) (*Server, error) {
+	tlsConfig := defaultTLSConfig()
+	tlsConfig.Certificates = certificates
+
+	return NewWithTLSConfig(ctx, host, tlsConfig, conf)
+}
+
+// NewFromPEM initializes server that uses PEM-encoded certificate and key.
+func NewFromPEM(ctx context.Context, host string, certPEM []byte, keyPEM []byte, conf *Config) (*Server, error) {
+	server := newServer(ctx, host, conf)
+
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, server.FormatError(fmt.Errorf("error getting key pair: %w", err))
+	}
+
+	tlsConfig := defaultTLSConfig()
+	tlsConfig.Certificates = []tls.Certificate{certificate}
+
+	err = server.setTLSConfig(tlsConfig)
+	if err != nil {
+		return nil, server.FormatError(err)
+	}
+
+	return server, nil
+}
+
+// NewWithGetCertificate initializes server that asks getCertificate for certificate on every handshake.
+func NewWithGetCertificate(ctx context.Context, host string,
+	getCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error), conf *Config,
+) (*Server, error) {
+	tlsConfig := defaultTLSConfig()
+	tlsConfig.GetCertificate = getCertificate
+
+	return NewWithTLSConfig(ctx, host, tlsConfig, conf)
+}
+
+// NewWithSNI initializes server that serves several hostnames, each with its own certificate.
+// Certificate is picked by server name (SNI) client requested: exact name first, then wildcard name
+// (e.g. "*.example.com"). Handshakes with other names (or without a name) use fallback.
+// If fallback is nil, such handshakes fail with ErrUnknownServerName.
+func NewWithSNI(ctx context.Context, host string, certificates map[string]tls.Certificate,
+	fallback *tls.Certificate, conf *Config,
+) (*Server, error) {
+	server := newServer(ctx, host, conf)
+
+	if len(certificates) == 0 && fallback == nil {
+		return nil, server.FormatError(ErrNoCertificate)
+	}
+
+	server.hostCertificates = make(map[string]*tls.Certificate, len(certificates))
+
+	for name, certificate := range certificates {
+		certificate := certificate
+		server.hostCertificates[strings.ToLower(name)] = &certificate
+	}
+
+	server.certificate = fallback
+
+	tlsConfig := defaultTLSConfig()
+	tlsConfig.GetCertificate = server.getCertificate
+
+	err := server.setTLSConfig(tlsConfig)
+	if err != nil {
+		return nil, server.FormatError(err)
+	}
+
+	return server, nil
+}
+
+// setTLSConfig sets copy of tlsConfig as server TLS config and applies TLS policy and mutual TLS settings
+// of server config. Config must provide server certificate.
+func (s *Server) setTLSConfig(tlsConfig *tls.Config) error {
+	if tlsConfig == nil || (len(tlsConfig.Certificates) == 0 &&
+		tlsConfig.GetCertificate == nil && tlsConfig.GetConfigForClient == nil) {
+		return ErrNoCertificate
+	}
+
+	s.tlsConfig = tlsConfig.Clone()
+
+	policy := conn.TLSPolicy{} //nolint:exhaustruct // Empty policy keeps TLS config as is
+	if s.sConfig.TLSPolicy != nil {
+		policy = *s.sConfig.TLSPolicy
+	}
+
+	err := policy.Validate()
+	if err != nil {
+		return err
+	}
+
+	policy.Apply(s.tlsConfig)
+
+	if s.sConfig.ClientCAs != nil {
+		s.tlsConfig.ClientCAs = s.sConfig.ClientCAs
+		s.tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	if s.sConfig.VerifyPeer != nil {
+		s.tlsConfig.VerifyConnection = chainVerifyConnection(s.tlsConfig.VerifyConnection, s.sConfig.VerifyPeer)
+	}
+
+	return nil
+}
+
+// chainVerifyConnection returns VerifyConnection callback that calls verify and then verifyPeer
+// for identity of client.
+func chainVerifyConnection(verify func(tls.ConnectionState) error,
+	verifyPeer func(*conn.PeerIdentity) error,
+) func(tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		if verify != nil {
+			err := verify(state)
+			if err != nil {
+				return err
+			}
+		}
+
+		return verifyPeer(conn.NewPeerIdentity(state))
+	}
+}
+
+// defaultTLSConfig returns TLS config server uses unless another one is provided.
+func defaultTLSConfig() *tls.Config {
+	return &tls.Config{MinVersion: tls.VersionTLS12} //nolint:exhaustruct // false alarm
+}
+
+// newServer initializes server instance without TLS config.
+func newServer(ctx context.Context, host string, conf *Config) *Server { //nolint: funlen // false alarm
 	server := new(Server)
 	server.timeStart = time.Now()
 	server.host = host
@@ -20,6 +189,7 @@ func New(ctx context.Context, host string, cert string, key string, conf *Config
 	server.serverDoneChan = make(chan bool)
 	server.connChan = make(chan *conn.Connection)
 	server.connPool = make(map[string]*conn.Connection)
+	server.groups = make(map[string]map[string]*conn.Connection)
 	server.stat = make(map[string]Stat)
 	server.statOverall = new(Stat)
 	server.connPoolMutex = sync.RWMutex{}
@@ -31,7 +201,8 @@ func New(ctx context.Context, host string, cert string, key string, conf *Config
 		Stable:      false,
 		ReleaseNote: "beta",
 	}
-	server.ctx = ctx
+	server.parentCtx = ctx
+	server.closing = make(chan struct{})
 
 	if conf == nil {
 		conf = new(Config)
@@ -42,6 +213,20 @@ func New(ctx context.Context, host string, cert string, key string, conf *Config
 		conf.MessageTerminator = '\n'
 	}
 
+	// Default framing is the terminator-based one.
+	if conf.Framer == nil {
+		delimiter := conf.MessageDelimiter
+		if len(delimiter) == 0 {
+			delimiter = []byte{conf.MessageTerminator}
+		}
+
+		if conf.MessageEscaping {
+			conf.Framer = conn.NewEscapingDelimiterFramer(delimiter)
+		} else {
+			conf.Framer = conn.NewDelimiterFramer(delimiter)
+		}
+	}
+
 	// Default buffer is 128 B.
 	if conf.BufferSize == 0 {
 		conf.BufferSize = 128
@@ -57,23 +242,20 @@ func New(ctx context.Context, host string, cert string, key string, conf *Config
 		conf.KeepInactiveConnections = 4320
 	}
 
-	if conf.ErrorPrefix == "" {
-		conf.ErrorPrefix = "TLS_SERVER"
+	// Default handshake timeout is 10 seconds.
+	if conf.HandshakeTimeout == 0 {
+		conf.HandshakeTimeout = 10 * time.Second
 	}
 
-	server.sConfig = conf
-
-	certificate, err := tls.LoadX509KeyPair(cert, key)
-	if err != nil {
-		return nil, server.FormatError(fmt.Errorf("error getting key pair: %w", err))
+	if conf.MaxHandshakes > 0 {
+		server.handshakes = make(chan struct{}, conf.MaxHandshakes)
 	}
 
-	server.tlsConfig = new(tls.Config)
-	server.tlsConfig.Certificates = []tls.Certificate{certificate}
-	server.tlsConfig.MinVersion = tls.VersionTLS12
+	if conf.ErrorPrefix == "" {
+		conf.ErrorPrefix = "TLS_SERVER"
+	}
 
-	// Start server admin.
-	go server.adminRoutine()
+	server.sConfig = conf
 
-	return server, nil
+	return server
 }
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFromMemory(t *testing.T) {
+	ctx := context.Background()
+	certificate, certPEM, keyPEM := newTestCertificate(t, "localhost", "127.0.0.1")
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+
+	srv, err := New(ctx, "localhost", certFile, keyFile, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, srv.certificate)
+
+	srv, err = NewFromPEM(ctx, "localhost", certPEM, keyPEM, nil)
+	require.NoError(t, err)
+	assert.Len(t, srv.tlsConfig.Certificates, 1)
+
+	_, err = NewFromPEM(ctx, "localhost", certPEM, []byte("bad key"), nil)
+	assert.Error(t, err)
+
+	srv, err = NewWithCertificates(ctx, "localhost", nil, certificate)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), srv.tlsConfig.MinVersion)
+
+	_, err = NewWithCertificates(ctx, "localhost", nil)
+	assert.True(t, errors.Is(err, ErrNoCertificate))
+
+	_, err = NewWithTLSConfig(ctx, "localhost", nil, nil)
+	assert.True(t, errors.Is(err, ErrNoCertificate))
+
+	// Server keeps its own copy of config.
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{certificate}, MinVersion: tls.VersionTLS13}
+	srv, err = NewWithTLSConfig(ctx, "localhost", tlsConfig, nil)
+	require.NoError(t, err)
+
+	tlsConfig.MinVersion = tls.VersionTLS10
+	assert.Equal(t, uint16(tls.VersionTLS13), srv.tlsConfig.MinVersion)
+}
+
+func TestNewWithGetCertificate(t *testing.T) {
+	certificate, _, _ := newTestCertificate(t, "localhost", "127.0.0.1")
+
+	var calls int32
+
+	srv, err := NewWithGetCertificate(context.Background(), "localhost",
+		func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			atomic.AddInt32(&calls, 1)
+
+			return &certificate, nil
+		}, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, srv.ListenAddr("127.0.0.1:0"))
+
+	client, _ := dialTestServer(t, srv, srv.Addrs()[0].String(), nil)
+	defer client.Close()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	require.NoError(t, srv.Stop())
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWithSNI(t *testing.T) {
+	first, _, _ := newTestCertificate(t, "a.example.com")
+	wildcard, _, _ := newTestCertificate(t, "*.tenant.com")
+	fallback, _, _ := newTestCertificate(t, "localhost")
+
+	srv, err := NewWithSNI(context.Background(), "localhost", map[string]tls.Certificate{
+		"A.example.com": first,
+		"*.tenant.com":  wildcard,
+	}, &fallback, nil)
+	require.NoError(t, err)
+	require.NoError(t, srv.ListenAddr("127.0.0.1:0"))
+
+	addr := srv.Addrs()[0].String()
+
+	dial := func(serverName string) (string, string) {
+		client, connection := dialTestServer(t, srv, addr,
+			&tls.Config{ServerName: serverName, InsecureSkipVerify: true}) //nolint:gosec // test
+		defer client.Close()
+
+		// Message is read after handshake is complete on server side.
+		_, err := client.Write([]byte("hi\n"))
+		require.NoError(t, err)
+
+		_, err = connection.GetMessage()
+		require.NoError(t, err)
+
+		return string(client.ConnectionState().PeerCertificates[0].Raw), connection.ServerName()
+	}
+
+	certificate, name := dial("a.example.com")
+	assert.Equal(t, string(first.Certificate[0]), certificate)
+	assert.Equal(t, "a.example.com", name)
+
+	certificate, name = dial("shop.tenant.com")
+	assert.Equal(t, string(wildcard.Certificate[0]), certificate)
+	assert.Equal(t, "shop.tenant.com", name)
+
+	// Wildcard covers one label only.
+	certificate, _ = dial("a.shop.tenant.com")
+	assert.Equal(t, string(fallback.Certificate[0]), certificate)
+
+	certificate, name = dial("")
+	assert.Equal(t, string(fallback.Certificate[0]), certificate)
+	assert.Empty(t, name)
+
+	require.NoError(t, srv.Stop())
+}
+
+func TestNewWithSNIWithoutFallback(t *testing.T) {
+	first, _, _ := newTestCertificate(t, "a.example.com")
+
+	srv, err := NewWithSNI(context.Background(), "localhost", map[string]tls.Certificate{"a.example.com": first}, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, srv.ListenAddr("127.0.0.1:0"))
+
+	go func() { _, _ = srv.AcceptConnection() }()
+
+	// Errors of failed handshake are read by server routines.
+	go func() {
+		for {
+			if srv.Error() == nil {
+				return
+			}
+		}
+	}()
+
+	_, err = tls.Dial("tcp", srv.Addrs()[0].String(),
+		&tls.Config{ServerName: "b.example.com", InsecureSkipVerify: true}) //nolint:gosec // test
+	assert.Error(t, err)
+
+	require.NoError(t, srv.Stop())
+
+	_, err = NewWithSNI(context.Background(), "localhost", nil, nil, nil)
+	assert.Error(t, err)
+}
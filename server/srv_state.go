@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// State is the lifecycle state of server.
+//
+// Server goes New -> Listening -> Stopping -> Stopped and can be started again
+// from Stopped to Listening. Stats and connection pool are kept between runs.
+type State int
+
+const (
+	// StateNew is the state of server that never listened.
+	StateNew State = iota
+	// StateListening is the state of server that accepts connections.
+	StateListening
+	// StateStopping is the state of server that is being stopped by Stop or Shutdown.
+	StateStopping
+	// StateStopped is the state of server that was stopped and can listen again.
+	StateStopped
+)
+
+func (st State) String() string {
+	switch st {
+	case StateNew:
+		return "new"
+	case StateListening:
+		return "listening"
+	case StateStopping:
+		return "stopping"
+	case StateStopped:
+		return "stopped"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(st))
+	}
+}
+
+// allowedTransitions holds states server can move into from each state.
+var allowedTransitions = map[State][]State{ //nolint:gochecknoglobals // It's OK
+	StateNew:       {StateListening},
+	StateListening: {StateStopping},
+	StateStopping:  {StateStopped},
+	StateStopped:   {StateListening},
+}
+
+// State returns current lifecycle state of server.
+func (s *Server) State() State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.state
+}
+
+// checkTransition returns ErrInvalidTransition if server can't move into state to.
+// Caller must hold s.mu.
+func (s *Server) checkTransition(to State) error {
+	for _, allowed := range allowedTransitions[s.state] {
+		if allowed == to {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, s.state, to)
+}
+
+// setState moves server into state to. Caller must hold s.mu.
+func (s *Server) setState(to State) error {
+	err := s.checkTransition(to)
+	if err != nil {
+		return err
+	}
+
+	s.state = to
+	s.isActive = to == StateListening
+
+	return nil
+}
+
+// startRun moves server into listening state and starts routines of the new run:
+// one accepting routine per listener. Caller must hold s.mu.
+func (s *Server) startRun(listeners []net.Listener) error {
+	err := s.setState(StateListening)
+	if err != nil {
+		return err
+	}
+
+	// Channel of the first run is made by New.
+	if s.closing == nil || isClosed(s.closing) {
+		s.closing = make(chan struct{})
+	}
+
+	s.ctx, s.cancel = context.WithCancel(s.parentCtx)
+	s.listeners = listeners
+
+	go s.adminRoutine(s.closing)
+
+	if s.certFile != "" && s.sConfig.CertReloadInterval > 0 {
+		go s.watchCertificates(s.sConfig.CertReloadInterval, s.closing)
+	}
+
+	for _, listener := range listeners {
+		go s.accept(s.ctx, listener, s.closing)
+	}
+
+	return nil
+}
+
+// closingChan returns channel that is closed when current run is stopped.
+func (s *Server) closingChan() chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.closing
+}
+
+// isClosed returns true if ch is closed.
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerLifecycle(t *testing.T) {
+	srv := GetEmptyTestServer()
+	srv.state = StateNew
+
+	// Server that never listened can't be stopped.
+	assert.True(t, errors.Is(srv.Stop(), ErrInvalidTransition))
+	assert.Equal(t, StateNew, srv.State())
+
+	run := func() net.Listener {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		srv.mu.Lock()
+		err = srv.startRun([]net.Listener{listener})
+		srv.mu.Unlock()
+		require.NoError(t, err)
+
+		return listener
+	}
+
+	for i := 0; i < 2; i++ {
+		listener := run()
+		assert.Equal(t, StateListening, srv.State())
+		assert.True(t, srv.IsActive())
+
+		// Server accepts connections in every run.
+		client, err := net.Dial("tcp", listener.Addr().String())
+		require.NoError(t, err)
+
+		connection, err := srv.AcceptConnection()
+		require.NoError(t, err)
+		assert.NotNil(t, connection)
+
+		require.NoError(t, srv.Stop())
+		assert.Equal(t, StateStopped, srv.State())
+		assert.False(t, srv.IsActive())
+
+		_, err = srv.AcceptConnection()
+		assert.True(t, errors.Is(err, ErrServerClosed))
+
+		_, err = net.Dial("tcp", listener.Addr().String())
+		assert.Error(t, err)
+
+		client.Close()
+	}
+
+	// Listening server can't listen again.
+	run()
+
+	srv.mu.Lock()
+	err := srv.startRun(nil)
+	srv.mu.Unlock()
+	assert.True(t, errors.Is(err, ErrInvalidTransition))
+	require.NoError(t, srv.Stop())
+}
@@ -1,27 +1,158 @@
 package server
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lazybark/go-tls-server/conn"
+)
+
+// shutdownPollInterval is how often Shutdown checks connections for being idle.
+const shutdownPollInterval = 50 * time.Millisecond
+
+// Stop stops accepting new connections and closes all connections at once.
+// Channels of server are not closed, so it's safe for other routines to use server during and after Stop:
+// Error returns nil and AcceptConnection returns ErrServerClosed. Stopped server can listen again.
+//
+// Only listening server can be stopped, otherwise error wraps ErrInvalidTransition.
 func (s *Server) Stop() error {
-	s.SetActive(false)
-	s.cancel()
+	err := s.beginStop()
+	if err != nil {
+		return s.FormatError(fmt.Errorf("[Stop] %w", err))
+	}
+
+	defer s.endStop()
+
+	s.connPoolMutex.RLock()
+	defer s.connPoolMutex.RUnlock()
+
+	for _, c := range s.connPool {
+		_ = c.Abort()
+	}
+
+	return nil
+}
+
+// Shutdown gracefully stops the server. It stops accepting new connections, sends GoingAwayMessage
+// (if set) to every open connection and then waits for connections to finish their work:
+// connection is closed as soon as it has no partially read messages and no messages in flight
+// (delivered, but not taken yet, or being handled by Serve). When ctx is done, remaining connections
+// are closed by force, including the ones that are still being written GoingAwayMessage.
+//
+// It returns number of connections that were closed gracefully (drained) and closed by force (killed).
+// Error is returned if ctx was done before all connections were drained.
+func (s *Server) Shutdown(ctx context.Context) (int, int, error) {
+	err := s.beginStop()
+	if err != nil {
+		return 0, 0, s.FormatError(fmt.Errorf("[Shutdown] %w", err))
+	}
+
+	defer s.endStop()
+
+	var connections []*conn.Connection
+
+	s.connPoolMutex.RLock()
+	for _, c := range s.connPool {
+		if !c.Closed() {
+			connections = append(connections, c)
+		}
+	}
+	s.connPoolMutex.RUnlock()
+
+	killed := 0
+
+	if len(s.sConfig.GoingAwayMessage) > 0 {
+		_, err := s.sendMany(ctx, connections, s.sConfig.GoingAwayMessage, nil)
+
+		// Connections that were still being written when ctx was done are closed by force.
+		var broadcastErr *BroadcastError
+		if errors.As(err, &broadcastErr) {
+			written := connections[:0]
+
+			for _, c := range connections {
+				if errors.Is(broadcastErr.Errors[c.ID()], ErrSendTimeout) {
+					_ = c.Abort()
+					killed++
 
-	s.listener.Close()
+					continue
+				}
 
-	var err error
+				written = append(written, c)
+			}
 
-	s.connPoolMutex.Lock()
+			connections = written
+		}
+	}
+
+	ticker := time.NewTicker(shutdownPollInterval)
+	defer ticker.Stop()
+
+	drained := 0
+
+	for {
+		busy := connections[:0]
+
+		for _, c := range connections {
+			// Remote may close connection after going away message.
+			if c.Closed() {
+				drained++
+
+				continue
+			}
+
+			if c.Idle() {
+				_ = c.Abort()
+				drained++
+
+				continue
+			}
+
+			busy = append(busy, c)
+		}
+
+		connections = busy
 
-	for _, conn := range s.connPool {
-		err = conn.Close()
-		if err != nil && !s.sConfig.SuppressErrors {
-			s.errChan <- err
+		if len(connections) == 0 && killed == 0 {
+			return drained, 0, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			for _, c := range connections {
+				_ = c.Abort()
+			}
+
+			return drained, killed + len(connections), s.FormatError(fmt.Errorf("[Shutdown] %w", ctx.Err()))
+		case <-ticker.C:
 		}
 	}
+}
 
-	s.connPoolMutex.Unlock()
+// beginStop marks server as stopping, unblocks routines that wait on server channels and closes listeners.
+func (s *Server) beginStop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	// At this point no routine will be left that can write in these channels.
-	close(s.connChan)
-	close(s.errChan)
+	err := s.setState(StateStopping)
+	if err != nil {
+		return err
+	}
+
+	close(s.closing)
+	s.cancel()
+
+	closeListeners(s.listeners)
+	s.listeners = nil
 
 	return nil
 }
+
+// endStop marks server as stopped, so it can listen again.
+func (s *Server) endStop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_ = s.setState(StateStopped)
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/lazybark/go-tls-server/conn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdown(t *testing.T) {
+	srv := GetEmptyTestServer()
+	srv.sConfig.GoingAwayMessage = []byte("bye")
+
+	release := make(chan struct{})
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(HandlerFunc(func(w ResponseWriter, m *conn.Message) {
+			<-release
+		}))
+	}()
+
+	newPipeConnection := func() (*conn.Connection, net.Conn) {
+		local, remote := net.Pipe()
+
+		c, err := conn.NewConnection(remote.RemoteAddr(), remote, '\n')
+		require.NoError(t, err)
+
+		srv.addToPool(c)
+		go srv.receive(c)
+		srv.connChan <- c
+
+		return c, local
+	}
+
+	idle, idlePeer := newPipeConnection()
+	busy, busyPeer := newPipeConnection()
+	partial, partialPeer := newPipeConnection()
+
+	_, err := busyPeer.Write([]byte("slow\n"))
+	require.NoError(t, err)
+	_, err = partialPeer.Write([]byte("partial"))
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool { return !busy.Idle() && !partial.Idle() }, time.Second, time.Millisecond*10)
+
+	// Peers read going away message.
+	for _, peer := range []net.Conn{idlePeer, busyPeer, partialPeer} {
+		go func(peer net.Conn) { _, _ = io.Copy(io.Discard, peer) }(peer)
+	}
+
+	go func() {
+		time.Sleep(time.Millisecond * 100)
+		close(release)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*500)
+	defer cancel()
+
+	drained, killed, err := srv.Shutdown(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, 2, drained)
+	assert.Equal(t, 1, killed)
+
+	assert.True(t, idle.Closed())
+	assert.True(t, busy.Closed())
+	assert.True(t, partial.Closed())
+
+	assert.True(t, errors.Is(<-serveErr, ErrServerClosed))
+	assert.Nil(t, srv.Error())
+
+	// Server can't be stopped twice.
+	assert.True(t, errors.Is(srv.Stop(), ErrInvalidTransition))
+	assert.Equal(t, StateStopped, srv.State())
+}
+
+func TestShutdownStuckGoingAway(t *testing.T) {
+	srv := GetEmptyTestServer()
+	srv.sConfig.GoingAwayMessage = []byte("bye")
+
+	// Peer never reads, so going away message can't be written.
+	local, remote := net.Pipe()
+	defer local.Close()
+
+	stuck, err := conn.NewConnection(remote.RemoteAddr(), remote, '\n')
+	require.NoError(t, err)
+	srv.addToPool(stuck)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+
+	started := time.Now()
+
+	drained, killed, err := srv.Shutdown(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, 0, drained)
+	assert.Equal(t, 1, killed)
+	assert.True(t, stuck.Closed())
+	assert.Less(t, time.Since(started), time.Second)
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"testing"
+
+	"github.com/lazybark/go-tls-server/conn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSPolicy(t *testing.T) {
+	certificate, _, _ := newTestCertificate(t, "localhost")
+
+	invalid := conn.TLSPolicy{MinVersion: tls.VersionTLS10} //nolint:exhaustruct // test
+	_, err := NewWithCertificates(context.Background(), "localhost", &Config{TLSPolicy: &invalid}, certificate)
+	assert.True(t, errors.Is(err, conn.ErrInvalidTLSPolicy))
+
+	policy := conn.ModernTLSPolicy()
+	policy.NextProtos = []string{"app/1"}
+
+	srv, err := NewWithCertificates(context.Background(), "localhost", &Config{TLSPolicy: &policy}, certificate)
+	require.NoError(t, err)
+	require.NoError(t, srv.ListenAddr("127.0.0.1:0"))
+
+	go func() {
+		for {
+			if srv.Error() == nil {
+				return
+			}
+		}
+	}()
+
+	addr := srv.Addrs()[0].String()
+
+	client, _ := dialTestServer(t, srv, addr, &tls.Config{
+		NextProtos:         []string{"app/1"},
+		InsecureSkipVerify: true, //nolint:gosec // test
+	})
+	defer client.Close()
+
+	assert.Equal(t, uint16(tls.VersionTLS13), client.ConnectionState().Version)
+	assert.Equal(t, "app/1", client.ConnectionState().NegotiatedProtocol)
+
+	// TLS 1.2 client is rejected by modern policy.
+	_, err = tls.Dial("tcp", addr, &tls.Config{MaxVersion: tls.VersionTLS12, InsecureSkipVerify: true}) //nolint:gosec // test
+	assert.Error(t, err)
+
+	require.NoError(t, srv.Stop())
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"time"
+
+	"github.com/lazybark/go-tls-server/conn"
 )
 
 // Stat holds server statistic.
@@ -11,6 +13,9 @@ type Stat struct {
 	received int
 	sent     int
 	errors   int
+
+	// handshakeErrors are counted apart from read/write errors.
+	handshakeErrors int
 }
 
 var ErrNoStatForTheDay = errors.New("no stat")
@@ -33,6 +38,15 @@ func (s *Server) StatsOverall() (int, int, int, error) {
 	return s.statOverall.sent, s.statOverall.received, s.statOverall.errors, nil
 }
 
+// StatsHandshakeErrors returns number of failed TLS handshakes for all periods of time.
+// They are not included in errors returned by Stats and StatsOverall.
+func (s *Server) StatsHandshakeErrors() int {
+	s.statMutex.Lock()
+	defer s.statMutex.Unlock()
+
+	return s.statOverall.handshakeErrors
+}
+
 // Stats returns server stats for specified day or error in case date is not in stat.
 func (s *Server) Stats(y int, m int, d int) (int, int, int, error) {
 	date := fmt.Sprintf(statKeyPattern, y, m, d)
@@ -57,6 +71,20 @@ func (s *Server) StatsConnections() (int, error) {
 	return len(s.connPool), nil
 }
 
+// StatsSnapshots returns stats of every connection in pool, including negotiated TLS state
+// (version, cipher suite, ALPN protocol, etc.).
+func (s *Server) StatsSnapshots() []conn.StatsSnapshot {
+	s.connPoolMutex.RLock()
+	defer s.connPoolMutex.RUnlock()
+
+	snapshots := make([]conn.StatsSnapshot, 0, len(s.connPool))
+	for _, c := range s.connPool {
+		snapshots = append(snapshots, c.Snapshot())
+	}
+
+	return snapshots
+}
+
 // addRecBytes adds bytes to stat of current day.
 func (s *Server) addRecBytes(count int) {
 	if count < 0 {
@@ -72,7 +100,7 @@ func (s *Server) addRecBytes(count int) {
 		v.received += count
 		s.stat[date] = v
 	} else {
-		s.stat[date] = Stat{received: count, sent: 0, errors: 0}
+		s.stat[date] = Stat{received: count, sent: 0, errors: 0, handshakeErrors: 0}
 	}
 
 	s.statOverall.received += count
@@ -93,7 +121,7 @@ func (s *Server) addSentBytes(count int) {
 		v.sent += count
 		s.stat[date] = v
 	} else {
-		s.stat[date] = Stat{sent: count, received: 0, errors: 0}
+		s.stat[date] = Stat{sent: count, received: 0, errors: 0, handshakeErrors: 0}
 	}
 
 	s.statOverall.sent += count
@@ -114,12 +142,33 @@ func (s *Server) addErrors(count int) {
 		v.errors += count
 		s.stat[date] = v
 	} else {
-		s.stat[date] = Stat{errors: count, sent: 0, received: 0}
+		s.stat[date] = Stat{errors: count, sent: 0, received: 0, handshakeErrors: 0}
 	}
 
 	s.statOverall.errors += count
 }
 
+// addHandshakeErrors adds failed handshakes to stat of current day.
+func (s *Server) addHandshakeErrors(count int) {
+	if count < 0 {
+		return
+	}
+
+	date := getStatKey()
+
+	s.statMutex.Lock()
+	defer s.statMutex.Unlock()
+
+	if v, ok := s.stat[date]; ok {
+		v.handshakeErrors += count
+		s.stat[date] = v
+	} else {
+		s.stat[date] = Stat{handshakeErrors: count, errors: 0, sent: 0, received: 0}
+	}
+
+	s.statOverall.handshakeErrors += count
+}
+
 // StartedAt returns starting time.
 func (s *Server) StartedAt() time.Time { return s.timeStart }
 
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSStateSnapshot(t *testing.T) {
+	srv := newTestTLSServer(t)
+	srv.tlsConfig.NextProtos = []string{"app/1"}
+
+	require.NoError(t, srv.ListenAddr("127.0.0.1:0"))
+
+	client, connection := dialTestServer(t, srv, srv.Addrs()[0].String(), &tls.Config{
+		ServerName:         "localhost",
+		NextProtos:         []string{"app/1"},
+		MaxVersion:         tls.VersionTLS12,
+		CipherSuites:       []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+		InsecureSkipVerify: true, //nolint:gosec // test
+	})
+	defer client.Close()
+
+	// Message is read after handshake is complete on server side.
+	_, err := client.Write([]byte("hi\n"))
+	require.NoError(t, err)
+
+	_, err = connection.GetMessage()
+	require.NoError(t, err)
+
+	state := connection.TLSState()
+	require.NotNil(t, state)
+	assert.True(t, state.HandshakeComplete)
+	assert.Equal(t, "TLS 1.2", state.VersionName())
+	assert.Equal(t, "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256", state.CipherSuiteName())
+	assert.Equal(t, "app/1", state.NegotiatedProtocol)
+	assert.Equal(t, "localhost", state.ServerName)
+	assert.False(t, state.DidResume)
+	assert.Empty(t, state.PeerCertificates)
+
+	snapshots := srv.StatsSnapshots()
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, connection.ID(), snapshots[0].ID)
+	assert.Equal(t, len("hi\n"), snapshots[0].Received)
+	assert.Equal(t, *state, *snapshots[0].TLS)
+
+	require.NoError(t, srv.Stop())
+}
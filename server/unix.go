@@ -0,0 +1,60 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// staleSocketTimeout limits time to check if socket file is still served by someone.
+const staleSocketTimeout = time.Second
+
+// listenUnix listens on Unix domain socket at path. Socket file left by a process that is not
+// running anymore is removed, but socket that is still in use is not touched.
+// Socket file is removed once listener is closed.
+func listenUnix(path string) (net.Listener, error) {
+	err := removeStaleSocket(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return net.Listen("unix", path)
+}
+
+// removeStaleSocket removes socket file at path if nobody accepts connections on it.
+// It returns ErrSocketInUse if socket is served and ErrNotSocket if path is not a socket.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("error checking socket %s: %w", path, err)
+	}
+
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%w: %s", ErrNotSocket, path)
+	}
+
+	c, err := net.DialTimeout("unix", path, staleSocketTimeout)
+	if err == nil {
+		_ = c.Close()
+
+		return fmt.Errorf("%w: %s", ErrSocketInUse, path)
+	}
+
+	err = os.Remove(path)
+	if err != nil {
+		return fmt.Errorf("error removing stale socket %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// isUnixListener returns true if listener accepts connections on Unix domain socket.
+func isUnixListener(listener net.Listener) bool {
+	return listener.Addr().Network() == "unix"
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lazybark/go-tls-server/conn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenUnix(t *testing.T) {
+	srv := newTestTLSServer(t)
+	path := filepath.Join(t.TempDir(), "srv.sock")
+
+	require.NoError(t, srv.ListenAddr(conn.UnixScheme+path))
+	assert.Equal(t, "unix", srv.Addrs()[0].Network())
+
+	client, connection := dialTestServer(t, srv, conn.UnixScheme+path, nil)
+	defer client.Close()
+
+	_, err := client.Write([]byte("hello\n"))
+	require.NoError(t, err)
+
+	message, err := connection.GetMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(message.Bytes()))
+
+	// Socket in use is not replaced.
+	other := newTestTLSServer(t)
+	assert.True(t, errors.Is(other.ListenAddr(conn.UnixScheme+path), ErrSocketInUse))
+
+	// Socket file is removed on stop.
+	require.NoError(t, srv.Stop())
+
+	_, err = os.Stat(path)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestListenUnixPlain(t *testing.T) {
+	srv := newTestTLSServer(t)
+	srv.sConfig.PlainUnixSockets = true
+	path := filepath.Join(t.TempDir(), "srv.sock")
+
+	require.NoError(t, srv.ListenAddr(conn.UnixScheme+path))
+
+	connections := make(chan *conn.Connection, 1)
+
+	go func() {
+		connection, _ := srv.AcceptConnection()
+		connections <- connection
+	}()
+
+	client, err := net.Dial("unix", path)
+	require.NoError(t, err)
+
+	defer client.Close()
+
+	_, err = client.Write([]byte("plain\n"))
+	require.NoError(t, err)
+
+	message, err := (<-connections).GetMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "plain", string(message.Bytes()))
+
+	require.NoError(t, srv.Stop())
+}
+
+func TestListenUnixStaleSocket(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "srv.sock")
+
+	// Crashed process leaves socket file behind.
+	listener, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, listener.Close())
+
+	_, err = os.Stat(path)
+	require.NoError(t, err)
+
+	srv := newTestTLSServer(t)
+	require.NoError(t, srv.ListenAddr(conn.UnixScheme+path))
+	require.NoError(t, srv.Stop())
+
+	// Regular file is never removed.
+	file := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(file, []byte("data"), 0o600))
+
+	srv = newTestTLSServer(t)
+	assert.True(t, errors.Is(srv.ListenAddr(conn.UnixScheme+file), ErrNotSocket))
+
+	_, err = os.Stat(file)
+	assert.NoError(t, err)
+}